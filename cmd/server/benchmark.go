@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/sender"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// runBenchmark выполняет фиксированную нагрузку без веб-интерфейса и SSE, печатает итоги
+// в stdout и возвращает код выхода: 1, если доля ошибок превысила порог (в процентах)
+func runBenchmark(configPath string, requests int, maxFailureRate float64) int {
+	cfg, err := loadConfigFile(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	cfg.MaxRequests = requests
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	logChan := make(chan sender.LogEntry, 100)
+	printed := make(chan struct{})
+	go printConsoleLogs(logChan, cfg.StdoutLogLevel, printed)
+
+	runID := sender.NewRunID()
+	logFunc := func(level, message string, meta telegram.LogMeta) {
+		entry := sender.NewLogEntry(level, message, meta)
+		entry.RunID = runID
+		sender.Emit(logChan, entry)
+	}
+	client, err := newBenchClient(cfg, cfg.ProxyURL, logFunc)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	fmt.Println(i18n.T("bench.started", requests, maxFailureRate))
+	fmt.Println(i18n.T("bench.runID", runID))
+	if cfg.RunLabel != "" {
+		fmt.Println(i18n.T("bench.runLabel", cfg.RunLabel))
+	}
+	stats := sender.NewStats()
+	snd := sender.NewSender(cfg, client, stats, logChan)
+	snd.SetRunID(runID)
+	if cfg.Canary {
+		if err := snd.Canary(ctx); err != nil {
+			close(logChan)
+			<-printed
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	started := time.Now()
+	reason := snd.Start(ctx)
+	// Контекст отменяется только сигналом
+	if ctx.Err() != nil {
+		reason = sender.StopSignal
+	}
+
+	close(logChan)
+	<-printed
+
+	snap := stats.Snapshot()
+	if cfg.ReportFile != "" {
+		if err := sender.WriteReport(cfg.ReportFile, sender.NewReport(runID, reason, started, time.Now(), cfg, snap)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		} else {
+			fmt.Println(i18n.T("server.reportWritten", cfg.ReportFile))
+		}
+	}
+	failureRate := 0.0
+	if snap.Total > 0 {
+		failureRate = float64(snap.Errors) / float64(snap.Total) * 100
+	}
+	fmt.Println(i18n.T("bench.summary", snap.Total, snap.Success, snap.Errors, failureRate, reason))
+	for _, phase := range []string{sender.PhaseDNS, sender.PhaseConnect, sender.PhaseTLS, sender.PhaseTTFB, sender.PhaseBodyRead, sender.PhaseTotal} {
+		if ps, ok := snap.Phases[phase]; ok {
+			fmt.Println(i18n.T("bench.phase", phase, ps.Count, ps.AvgMs, ps.P50Ms, ps.P95Ms, ps.P99Ms, ps.MaxMs))
+		}
+	}
+
+	if failureRate > maxFailureRate {
+		fmt.Println(i18n.T("bench.failed", failureRate, maxFailureRate))
+		return 1
+	}
+	fmt.Println(i18n.T("bench.passed"))
+	return 0
+}
+
+// newBenchClient создаёт клиент Bot API по конфигурации бенчмарка через прокси proxyURL
+func newBenchClient(cfg *config.Config, proxyURL string, logFunc telegram.LogFunc) (*telegram.Client, error) {
+	return telegram.NewClient(cfg.Timeout, proxyURL, cfg.DisableKeepAlive, cfg.RequestEncoding, logFunc,
+		telegram.WithRequestDump(cfg.LogRequestDump), telegram.WithRequestSigning(cfg.SigningHeader, cfg.SigningSecret),
+		telegram.WithDNSServer(cfg.DNSServer), telegram.WithDoH(cfg.DoHEndpoint), telegram.WithLocalAddr(cfg.LocalAddr),
+		telegram.WithMaxResponseBody(cfg.MaxResponseBody), telegram.WithTLSDetails(cfg.VerifyTLSDetails, cfg.TLSExpiryWarning),
+		telegram.WithMaxRequestsPerConn(cfg.MaxRequestsPerConn), telegram.WithTraceSummary(cfg.TraceSummary),
+		telegram.WithDiscardResponseBody(cfg.DiscardResponseBody), telegram.WithProxyPool(cfg.Proxies, cfg.ProxyStickiness))
+}
+
+// loadConfigFile читает JSON-конфигурацию (в формате /api/config/update) поверх значений по умолчанию
+func loadConfigFile(path string) (*config.Config, error) {
+	cfg := config.Default()
+	if path == "" {
+		return cfg, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("чтение конфигурации: %w", err)
+	}
+	defer f.Close()
+	if err := config.Decode(f, cfg); err != nil {
+		return nil, fmt.Errorf("разбор конфигурации: %w", err)
+	}
+	return cfg, nil
+}
+
+// printConsoleLogs печатает результаты запросов, предупреждения и ошибки; трейсинг пропускается.
+// Заданный level (StdoutLogLevel) заменяет это правило порогом уровня
+func printConsoleLogs(logChan <-chan sender.LogEntry, level string, done chan<- struct{}) {
+	defer close(done)
+	for entry := range logChan {
+		if level == "" && entry.Level == "info" && entry.Category != sender.CategoryResult {
+			continue
+		}
+		if level != "" && config.LogLevelRank(entry.Level) < config.LogLevelRank(level) {
+			continue
+		}
+		fmt.Printf("%s [%s] %s\n", entry.Time.Format("15:04:05.000"), strings.ToUpper(entry.Level), entry.Message)
+	}
+}
@@ -1,31 +1,152 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/logfile"
 	"SendMsgTestForTG/internal/server"
+	"SendMsgTestForTG/internal/statsd"
+)
+
+// Сведения о сборке, задаются при сборке:
+// go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildTime=$(date -u +%FT%TZ)"
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildTime = "unknown"
 )
 
 func main() {
 	addr := flag.String("addr", ":8080", "Адрес для прослушивания")
+	metricsAddr := flag.String("metrics-addr", "", "Отдельный адрес для метрик Prometheus (/metrics), например :9090")
+	lang := flag.String("lang", "ru", "Язык сообщений лога (ru, en)")
+	logFile := flag.String("logfile", "", "Файл для записи логов (JSON lines) с ротацией по размеру")
+	logFileMaxSize := flag.Int("logfile-max-size", 100, "Размер файла лога для ротации, МБ")
+	logFileBackups := flag.Int("logfile-backups", 3, "Количество хранимых копий файла лога")
+	presetsFile := flag.String("presets", "", "JSON-файл для хранения пресетов конфигурации (/api/presets); без него пресеты живут до перезапуска")
+	readTimeout := flag.Duration("read-timeout", 30*time.Second, "Срок чтения запроса, включая тело (0 — без ограничения)")
+	writeTimeout := flag.Duration("write-timeout", 2*time.Minute, "Срок записи ответа; для SSE — срок записи каждого события (0 — без ограничения)")
+	idleTimeout := flag.Duration("idle-timeout", 2*time.Minute, "Время жизни простаивающего keep-alive соединения")
+	subscriberQueue := flag.Int("subscriber-queue", 256, "Очередь доставки логов на каждого подписчика SSE и файл лога, записей")
+	statsdAddr := flag.String("statsd", "", "Адрес StatsD (host:port) для метрик запросов по UDP")
+	statsdPrefix := flag.String("statsd-prefix", "tgtester", "Префикс имён метрик StatsD")
+	benchmark := flag.Bool("benchmark", false, "Выполнить бенчмарк без веб-интерфейса и выйти")
+	configPath := flag.String("config", "", "JSON-файл конфигурации для бенчмарка (формат /api/config/update)")
+	benchRequests := flag.Int("bench-requests", 20, "Количество запросов в бенчмарке")
+	benchMaxFailures := flag.Float64("bench-max-failure-rate", 10, "Допустимая доля ошибок бенчмарка, %")
+	preSendCommand := flag.String("pre-send-command", "", "Команда оболочки, которую разрешено указывать в preSendCommand конфигурации; без флага команды перед отправкой запрещены")
+	proxyBenchmark := flag.Bool("proxy-benchmark", false, "Сравнить прокси из списка proxies: прогнать -bench-requests запросов через каждый и выйти")
 	flag.Parse()
 
+	if err := i18n.SetLang(i18n.Lang(*lang)); err != nil {
+		log.Fatal(err)
+	}
+
+	if *proxyBenchmark {
+		os.Exit(runProxyBenchmark(*configPath, *benchRequests))
+	}
+	if *benchmark {
+		os.Exit(runBenchmark(*configPath, *benchRequests, *benchMaxFailures))
+	}
+
 	srv := server.NewServer()
+	srv.SetBuildInfo(server.BuildInfo{Version: version, Commit: commit, BuildTime: buildTime})
+	srv.SetWriteTimeout(*writeTimeout)
+	srv.SetSubscriberQueue(*subscriberQueue)
+	srv.SetPreSendCommand(*preSendCommand)
+	if *logFile != "" {
+		w, err := logfile.Open(*logFile, int64(*logFileMaxSize)<<20, *logFileBackups)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer w.Close()
+		srv.AddLogSink(w)
+		log.Print(i18n.T("server.logFile", *logFile, *logFileMaxSize, *logFileBackups))
+	}
+	if *presetsFile != "" {
+		if err := srv.LoadPresets(*presetsFile); err != nil {
+			log.Fatal(err)
+		}
+		log.Print(i18n.T("server.presetsFile", *presetsFile))
+	}
+	if *statsdAddr != "" {
+		sink, err := statsd.New(*statsdAddr, *statsdPrefix)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer sink.Close()
+		srv.AddResultSink(sink)
+		log.Print(i18n.T("server.statsdSink", *statsdAddr, *statsdPrefix))
+	}
+	srv.AddStdoutSink(os.Stdout)
 	srv.StartLogBroadcaster()
 
 	http.HandleFunc("/api/config", srv.GetConfig)
 	http.HandleFunc("/api/config/update", srv.UpdateConfig)
+	http.HandleFunc("/api/config/effective", srv.GetEffectiveConfig)
 	http.HandleFunc("/api/start", srv.Start)
 	http.HandleFunc("/api/stop", srv.Stop)
+	http.HandleFunc("/api/run/interval", srv.SetInterval)
 	http.HandleFunc("/api/status", srv.GetStatus)
+	http.HandleFunc("/api/stats", srv.GetStats)
+	http.HandleFunc("/api/version", srv.GetVersion)
+	http.HandleFunc("/api/presets", srv.Presets)
+	http.HandleFunc("/api/presets/{name}/apply", srv.ApplyPreset)
+	http.HandleFunc("/api/proxy/test", srv.TestProxy)
+	http.HandleFunc("/api/bot/webhook", srv.BotWebhook)
 	http.HandleFunc("/api/logs", srv.LogsSSE)
+	http.HandleFunc("/api/events", srv.EventsSSE)
+	http.HandleFunc("/api/conns", srv.ConnsSSE)
 	http.Handle("/", http.FileServer(http.Dir("./web/static")))
 
-	log.Printf("Сервер запущен на http://localhost%s", *addr)
-	if err := http.ListenAndServe(*addr, nil); err != nil {
-		log.Fatal(err)
+	// Метрики слушают отдельный адрес: /metrics не попадает в основной API и не делит листенер с SSE
+	if *metricsAddr != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.HandleFunc("/metrics", srv.Metrics)
+		metricsServer := &http.Server{Addr: *metricsAddr, Handler: metricsMux, ReadTimeout: *readTimeout, WriteTimeout: *writeTimeout, IdleTimeout: *idleTimeout}
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil {
+				log.Fatal(err)
+			}
+		}()
+		log.Print(i18n.T("server.metricsListening", *metricsAddr))
 	}
+
+	// По сигналу завершения запуск останавливается штатно: итоговые события, отчёт и webhook
+	// успевают выполниться до выхода
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Сроки защищают от медленных клиентов; SSE-обработчики снимают общий WriteTimeout
+	// и продлевают срок записи перед каждым событием (Server.SetWriteTimeout)
+	httpServer := &http.Server{
+		Addr:         *addr,
+		ReadTimeout:  *readTimeout,
+		WriteTimeout: *writeTimeout,
+		IdleTimeout:  *idleTimeout,
+	}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+	log.Print(i18n.T("server.listening", *addr))
+
+	<-ctx.Done()
+	log.Print(i18n.T("server.shuttingDown"))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	srv.Shutdown(shutdownCtx)
+	// SSE-соединения бессрочные, поэтому сервер закрывается сразу, без ожидания
+	httpServer.Close()
 }
 
+// shutdownTimeout сколько ждать завершения запуска после сигнала
+const shutdownTimeout = 30 * time.Second
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/sender"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// runProxyBenchmark по очереди прогоняет одинаковую нагрузку через каждый прокси из Proxies
+// (для каждого — новый клиент и статистика), печатает сравнительную таблицу и пишет её
+// в ReportFile. Возвращает 1, если ни через один прокси не прошло ни одного запроса
+func runProxyBenchmark(configPath string, requests int) int {
+	cfg, err := loadConfigFile(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	cfg.MaxRequests = requests
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if len(cfg.Proxies) == 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("bench.noProxies"))
+		return 2
+	}
+
+	logChan := make(chan sender.LogEntry, 100)
+	printed := make(chan struct{})
+	go printConsoleLogs(logChan, cfg.StdoutLogLevel, printed)
+
+	runID := sender.NewRunID()
+	logFunc := func(level, message string, meta telegram.LogMeta) {
+		entry := sender.NewLogEntry(level, message, meta)
+		entry.RunID = runID
+		sender.Emit(logChan, entry)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	fmt.Println(i18n.T("bench.proxyStarted", len(cfg.Proxies), requests))
+	fmt.Println(i18n.T("bench.runID", runID))
+	if cfg.RunLabel != "" {
+		fmt.Println(i18n.T("bench.runLabel", cfg.RunLabel))
+	}
+	started := time.Now()
+	results := make([]sender.ProxyResult, 0, len(cfg.Proxies))
+	for i, proxy := range cfg.Proxies {
+		if ctx.Err() != nil {
+			break
+		}
+		fmt.Println(i18n.T("bench.proxyRun", i+1, len(cfg.Proxies), proxyLabel(proxy)))
+		results = append(results, benchProxy(ctx, cfg, proxy, runID, logChan, logFunc))
+	}
+
+	close(logChan)
+	<-printed
+
+	if cfg.ReportFile != "" {
+		report := sender.ProxyReport{
+			RunID:      runID,
+			RunLabel:   cfg.RunLabel,
+			Started:    started,
+			Finished:   time.Now(),
+			DurationMs: float64(time.Since(started).Microseconds()) / 1000,
+			Requests:   requests,
+			Config:     cfg.Redacted(),
+			Proxies:    results,
+		}
+		if err := sender.WriteProxyReport(cfg.ReportFile, report); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		} else {
+			fmt.Println(i18n.T("server.reportWritten", cfg.ReportFile))
+		}
+	}
+
+	fmt.Println(i18n.T("bench.proxyHeader"))
+	anySuccess := false
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Println(i18n.T("bench.proxyRowError", proxyLabel(r.ProxyURL), r.Error))
+			continue
+		}
+		fmt.Println(i18n.T("bench.proxyRow", proxyLabel(r.ProxyURL), r.Total, r.SuccessRate*100, r.AvgMs, r.P95Ms))
+		anySuccess = anySuccess || r.Success > 0
+	}
+	if !anySuccess {
+		return 1
+	}
+	return 0
+}
+
+// benchProxy выполняет нагрузку через один прокси со своим клиентом и статистикой
+func benchProxy(ctx context.Context, cfg *config.Config, proxy, runID string, logChan chan sender.LogEntry, logFunc telegram.LogFunc) sender.ProxyResult {
+	proxyCfg := *cfg
+	proxyCfg.ProxyURL = proxy
+	// Каждый прокси проверяется отдельно, пул с закреплением здесь не нужен
+	proxyCfg.ProxyStickiness = 0
+	client, err := newBenchClient(&proxyCfg, proxy, logFunc)
+	if err != nil {
+		result := sender.NewProxyResult(proxy, "", sender.StatsSnapshot{})
+		result.Error = err.Error()
+		return result
+	}
+
+	stats := sender.NewStats()
+	snd := sender.NewSender(&proxyCfg, client, stats, logChan)
+	snd.SetRunID(runID)
+	if proxyCfg.Canary {
+		if err := snd.Canary(ctx); err != nil {
+			result := sender.NewProxyResult(proxy, "", stats.Snapshot())
+			result.Error = err.Error()
+			return result
+		}
+	}
+	reason := snd.Start(ctx)
+	if ctx.Err() != nil {
+		reason = sender.StopSignal
+	}
+	return sender.NewProxyResult(proxy, reason, stats.Snapshot())
+}
+
+// proxyLabel подпись прокси в таблице: адрес без пароля или «прямое подключение»
+func proxyLabel(proxy string) string {
+	if proxy == "" {
+		return i18n.T("bench.proxyDirect")
+	}
+	return config.RedactProxyURL(proxy)
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadAliases читает файл псевдонимов чатов — JSON-объект {"псевдоним": "chat ID"}
+func LoadAliases(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAliasFile, err)
+	}
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAliasFile, err)
+	}
+	for alias, id := range aliases {
+		if id == "" || IsChatAlias(id) {
+			return nil, fmt.Errorf("%w: псевдоним %q должен указывать на числовой ID или @username, а не %q", ErrInvalidAliasFile, alias, id)
+		}
+	}
+	return aliases, nil
+}
+
+// IsChatAlias сообщает, что цель задана псевдонимом: это не числовой ID и не @username
+func IsChatAlias(target string) bool {
+	if strings.HasPrefix(target, "@") {
+		return false
+	}
+	_, err := strconv.ParseInt(target, 10, 64)
+	return err != nil
+}
+
+// validAliases проверяет, что файл псевдонимов читается и содержит все псевдонимы целей
+func (c *Config) validAliases() error {
+	aliases, err := LoadAliases(c.AliasFile)
+	if err != nil {
+		return err
+	}
+	for _, target := range c.Targets() {
+		if _, ok := aliases[target]; IsChatAlias(target) && !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownChatAlias, target)
+		}
+	}
+	return nil
+}
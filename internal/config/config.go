@@ -1,36 +1,623 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
 // Config содержит все настройки приложения
 type Config struct {
-	ProxyURL         string        `json:"proxyURL"`
-	Timeout          time.Duration `json:"timeout"`
-	Interval         time.Duration `json:"interval"`
-	ChatID           string        `json:"chatID"`
-	BotToken         string        `json:"botToken"`
-	MessageThreadID  string        `json:"messageThreadID"`
-	DisableKeepAlive bool          `json:"disableKeepAlive"`
+	ProxyURL string `json:"proxyURL"`
+	// Proxies прокси для режима сравнения (-proxy-benchmark); пустая строка — прямое подключение.
+	// ProxyStickiness > 0 — отправка через пул Proxies вместо ProxyURL со сменой прокси после стольких запросов подряд
+	Proxies                     []string          `json:"proxies"`
+	ProxyStickiness             int               `json:"proxyStickiness"`
+	Timeout                     time.Duration     `json:"timeout"`
+	Interval                    time.Duration     `json:"interval"`
+	ChatID                      string            `json:"chatID"`
+	ChatIDs                     []string          `json:"chatIDs"`
+	ChatSelection               string            `json:"chatSelection"`
+	AliasFile                   string            `json:"aliasFile"`
+	BotToken                    string            `json:"botToken"`
+	BotTokens                   []string          `json:"botTokens"`
+	PreSendCommand              string            `json:"preSendCommand"`
+	PreSendTimeout              time.Duration     `json:"preSendTimeout"`
+	PreSendField                string            `json:"preSendField"`
+	MessageThreadID             string            `json:"messageThreadID"`
+	BusinessConnectionID        string            `json:"businessConnectionID"`
+	DisableKeepAlive            bool              `json:"disableKeepAlive"`
+	MaxRequestsPerConn          int               `json:"maxRequestsPerConn"`
+	CleanupOnStop               bool              `json:"cleanupOnStop"`
+	CleanupTimeout              time.Duration     `json:"cleanupTimeout"`
+	RequestEncoding             string            `json:"requestEncoding"`
+	KeepAliveProbe              time.Duration     `json:"keepAliveProbe"`
+	UploadFile                  string            `json:"uploadFile"`
+	UploadCaption               string            `json:"uploadCaption"`
+	CompletionWebhook           string            `json:"completionWebhook"`
+	RunLabel                    string            `json:"runLabel"`
+	ReportFile                  string            `json:"reportFile"`
+	LogOverflowPolicy           string            `json:"logOverflowPolicy"`
+	StdoutLogLevel              string            `json:"stdoutLogLevel"`
+	SSELogLevel                 string            `json:"sseLogLevel"`
+	MessageText                 string            `json:"messageText"`
+	EditAfter                   time.Duration     `json:"editAfter"`
+	EditText                    string            `json:"editText"`
+	Messages                    []Message         `json:"messages"`
+	DetectDuplicates            bool              `json:"detectDuplicates"`
+	Entities                    json.RawMessage   `json:"entities"`
+	LinkPreviewOptions          json.RawMessage   `json:"linkPreviewOptions"`
+	TargetLatency               time.Duration     `json:"targetLatency"`
+	MaxWorkers                  int               `json:"maxWorkers"`
+	AdjustInterval              time.Duration     `json:"adjustInterval"`
+	RampTest                    *RampTest         `json:"rampTest"`
+	MaxRequests                 int               `json:"maxRequests"`
+	WarmupRequests              int               `json:"warmupRequests"`
+	WarmupDuration              time.Duration     `json:"warmupDuration"`
+	StartDelay                  time.Duration     `json:"startDelay"`
+	TrackClockSkew              bool              `json:"trackClockSkew"`
+	AppendTimestamp             bool              `json:"appendTimestamp"`
+	Timezone                    string            `json:"timezone"`
+	MaxBandwidth                int64             `json:"maxBandwidth"`
+	MaxRetries                  int               `json:"maxRetries"`
+	RequestTimeout              time.Duration     `json:"requestTimeout"`
+	RequestTimeoutMin           time.Duration     `json:"requestTimeoutMin"`
+	RequestTimeoutMax           time.Duration     `json:"requestTimeoutMax"`
+	HeaderTimeout               time.Duration     `json:"headerTimeout"`
+	HeaderTimeoutStep           time.Duration     `json:"headerTimeoutStep"`
+	RetryRate                   float64           `json:"retryRate"`
+	RetryBurst                  int               `json:"retryBurst"`
+	RetryMaxBackoff             time.Duration     `json:"retryMaxBackoff"`
+	PerCycleBudget              time.Duration     `json:"perCycleBudget"`
+	ChatSpacing                 time.Duration     `json:"chatSpacing"`
+	MinSleep                    time.Duration     `json:"minSleep"`
+	CatchUpPolicy               string            `json:"catchUpPolicy"`
+	LongMessagePolicy           string            `json:"longMessagePolicy"`
+	RetryJitter                 string            `json:"retryJitter"`
+	RetryOnStatus               []int             `json:"retryOnStatus"`
+	TreatOkFalseAs              string            `json:"treatOkFalseAs"`
+	AbortOnErrorCodes           []int             `json:"abortOnErrorCodes"`
+	VerboseFirstN               int               `json:"verboseFirstN"`
+	Canary                      bool              `json:"canary"`
+	SummaryInterval             time.Duration     `json:"summaryInterval"`
+	FailureInjectionRate        float64           `json:"failureInjectionRate"`
+	StopGrace                   time.Duration     `json:"stopGrace"`
+	DisableNotification         bool              `json:"disableNotification"`
+	ProtectContent              bool              `json:"protectContent"`
+	MessageEffectID             string            `json:"messageEffectID"`
+	AllowPaidBroadcast          bool              `json:"allowPaidBroadcast"`
+	AllowUnbounded              bool              `json:"allowUnbounded"`
+	ExtraParams                 map[string]string `json:"extraParams"`
+	ReplyToMessageID            string            `json:"replyToMessageID"`
+	AllowSendingWithoutReply    bool              `json:"allowSendingWithoutReply"`
+	TargetFallback              bool              `json:"targetFallback"`
+	QuietHoursStart             string            `json:"quietHoursStart"`
+	QuietHoursEnd               string            `json:"quietHoursEnd"`
+	DNSServer                   string            `json:"dnsServer"`
+	DoHEndpoint                 string            `json:"dohEndpoint"`
+	LocalAddr                   string            `json:"localAddr"`
+	MaxResponseBody             int64             `json:"maxResponseBody"`
+	DiscardResponseBody         bool              `json:"discardResponseBody"`
+	VerifyTLSDetails            bool              `json:"verifyTLSDetails"`
+	TLSExpiryWarning            time.Duration     `json:"tlsExpiryWarning"`
+	SigningHeader               string            `json:"signingHeader"`
+	SigningSecret               string            `json:"signingSecret"`
+	LogRequestDump              bool              `json:"logRequestDump"`
+	TraceSummary                bool              `json:"traceSummary"`
+	AutoStopAfterIdle           time.Duration     `json:"autoStopAfterIdle"`
+	FollowChatMigration         bool              `json:"followChatMigration"`
+	FallbackToPlainOnParseError bool              `json:"fallbackToPlainOnParseError"`
+	SSERetry                    time.Duration     `json:"sseRetry"`
+	StatsInterval               time.Duration     `json:"statsInterval"`
+}
+
+// Стратегии выбора чата для запроса (ChatSelection)
+const (
+	ChatSelectionRoundRobin        = "roundRobin"
+	ChatSelectionRandom            = "random"
+	ChatSelectionSequentialExhaust = "sequentialExhaust"
+)
+
+// Куда подставляется вывод PreSendCommand (PreSendField)
+const (
+	PreSendFieldBotToken = "botToken"
+	PreSendFieldText     = "text"
+)
+
+// PreSendPlaceholder метка в тексте сообщения, заменяемая выводом PreSendCommand при PreSendField=text
+const PreSendPlaceholder = "{preSend}"
+
+// Режимы джиттера пауз между повторами (RetryJitter)
+const (
+	RetryJitterNone  = "none"
+	RetryJitterFull  = "full"
+	RetryJitterEqual = "equal"
+)
+
+// Что делать, когда запрос занял больше интервала (CatchUpPolicy); пустое значение — immediate
+const (
+	CatchUpImmediate = "immediate"
+	CatchUpSkip      = "skip"
+	CatchUpResync    = "resync"
+)
+
+// Что делать с текстом длиннее 4096 символов (LongMessagePolicy); пустое значение — error
+const (
+	LongMessageError    = "error"
+	LongMessageTruncate = "truncate"
+	LongMessageSplit    = "split"
+)
+
+// Как учитываются ответы ok=false (TreatOkFalseAs); пустое значение — error
+const (
+	OkFalseError = "error"
+	OkFalseWarn  = "warn"
+	OkFalseRetry = "retry"
+)
+
+// Пороги уровня записей лога для stdout и SSE (StdoutLogLevel, SSELogLevel); off отключает вывод
+const (
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+	LogLevelOff   = "off"
+)
+
+// LogLevelRank порядок уровня записи или порога: info < warn < error < off; неизвестный — как info
+func LogLevelRank(level string) int {
+	switch level {
+	case LogLevelWarn:
+		return 1
+	case LogLevelError:
+		return 2
+	case LogLevelOff:
+		return 3
+	}
+	return 0
+}
+
+// QuietHoursLayout формат границ тихих часов (локальное время)
+const QuietHoursLayout = "15:04"
+
+// InQuietHours сообщает, попадает ли время в тихие часы [QuietHoursStart, QuietHoursEnd).
+// Интервал может переходить через полночь (например, 22:00–08:00)
+func (c *Config) InQuietHours(t time.Time) bool {
+	start, err := time.Parse(QuietHoursLayout, c.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(QuietHoursLayout, c.QuietHoursEnd)
+	if err != nil {
+		return false
+	}
+
+	now := t.Hour()*60 + t.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from <= to {
+		return now >= from && now < to
+	}
+	return now >= from || now < to
+}
+
+// Message вариант текста сообщения; выбирается случайно пропорционально весу
+type Message struct {
+	Text   string  `json:"text"`
+	Weight float64 `json:"weight"`
+}
+
+// RampTest параметры поиска максимальной устойчивой нагрузки: RPS растёт ступенями
+// до превышения порогов ошибок или задержки
+type RampTest struct {
+	StartRPS     float64       `json:"startRPS"`
+	StepRPS      float64       `json:"stepRPS"`
+	StepDuration time.Duration `json:"stepDuration"`
+	// MaxRPS завершает тест, когда ступень с этим RPS пройдена (0 — без ограничения)
+	MaxRPS float64 `json:"maxRPS"`
+	// MaxFailureRate допустимая доля ошибок на ступени, %
+	MaxFailureRate float64 `json:"maxFailureRate"`
+	// MaxP95 допустимый p95 длительности запроса на ступени (0 — не проверяется)
+	MaxP95 time.Duration `json:"maxP95"`
 }
 
 // Validate проверяет обязательные поля конфигурации
 func (c *Config) Validate() error {
-	if c.ChatID == "" {
+	if len(c.Targets()) == 0 {
 		return ErrChatIDRequired
 	}
-	if c.BotToken == "" {
+	// Токен может выдавать команда перед отправкой
+	if len(c.Tokens()) == 0 && (c.PreSendCommand == "" || c.PreSendField == PreSendFieldText) {
 		return ErrBotTokenRequired
 	}
+	switch c.PreSendField {
+	case "", PreSendFieldBotToken, PreSendFieldText:
+	default:
+		return ErrInvalidPreSend
+	}
+	if c.PreSendTimeout < 0 {
+		return ErrInvalidPreSend
+	}
+	if err := validateProxyURL(c.ProxyURL); err != nil {
+		return err
+	}
+	for _, proxy := range c.Proxies {
+		if err := validateProxyURL(proxy); err != nil {
+			return err
+		}
+	}
+	if c.ProxyStickiness < 0 || (c.ProxyStickiness > 0 && len(c.Proxies) == 0) {
+		return ErrInvalidProxyStickiness
+	}
+	if c.DNSServer != "" {
+		if _, _, err := net.SplitHostPort(c.DNSServer); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidDNSServer, err)
+		}
+	}
+	if c.DoHEndpoint != "" {
+		if u, err := url.Parse(c.DoHEndpoint); err != nil || u.Scheme != "https" || u.Host == "" {
+			return ErrInvalidDoHEndpoint
+		}
+	}
+	if c.LocalAddr != "" && net.ParseIP(c.LocalAddr) == nil {
+		host, _, err := net.SplitHostPort(c.LocalAddr)
+		if err != nil || net.ParseIP(host) == nil {
+			return fmt.Errorf("%w: %s", ErrInvalidLocalAddr, c.LocalAddr)
+		}
+	}
+	if c.AliasFile != "" {
+		if err := c.validAliases(); err != nil {
+			return err
+		}
+	}
+	switch c.ChatSelection {
+	case "", ChatSelectionRoundRobin, ChatSelectionRandom, ChatSelectionSequentialExhaust:
+	default:
+		return ErrInvalidChatSelection
+	}
+	switch c.RequestEncoding {
+	case "", "form", "json", "multipart":
+	default:
+		return ErrInvalidRequestEncoding
+	}
+	if len(c.Entities) > 0 {
+		var entities []json.RawMessage
+		if err := json.Unmarshal(c.Entities, &entities); err != nil {
+			return ErrEntitiesNotArray
+		}
+	}
+	if len(c.LinkPreviewOptions) > 0 && string(c.LinkPreviewOptions) != "null" {
+		if err := validLinkPreviewOptions(c.LinkPreviewOptions); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidLinkPreviewOptions, err)
+		}
+	}
+	if len(c.Messages) > 0 {
+		var total float64
+		for _, m := range c.Messages {
+			if m.Text == "" || m.Weight < 0 {
+				return ErrInvalidMessages
+			}
+			total += m.Weight
+		}
+		if total == 0 {
+			return ErrInvalidMessages
+		}
+	}
+	if r := c.RampTest; r != nil {
+		if r.StartRPS <= 0 || r.StepRPS <= 0 || r.StepDuration <= 0 || r.MaxRPS < 0 ||
+			r.MaxFailureRate < 0 || r.MaxFailureRate > 100 || r.MaxP95 < 0 {
+			return ErrInvalidRampTest
+		}
+	}
+	if c.MaxRetries < 0 || c.RetryRate < 0 || c.RetryBurst < 0 || c.HeaderTimeout < 0 || c.HeaderTimeoutStep < 0 || c.RetryMaxBackoff < 0 {
+		return ErrInvalidRetryBudget
+	}
+	if c.PerCycleBudget < 0 {
+		return ErrInvalidPerCycleBudget
+	}
+	if err := c.Target(c.ChatID).Validate(); err != nil {
+		return err
+	}
+	if _, err := c.Location(); err != nil {
+		return err
+	}
+	for _, status := range c.RetryOnStatus {
+		if status < 100 || status > 599 {
+			return fmt.Errorf("%w: %d", ErrInvalidRetryOnStatus, status)
+		}
+	}
+	for _, code := range c.AbortOnErrorCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("%w: %d", ErrInvalidAbortOnErrorCodes, code)
+		}
+	}
+	for name := range c.ExtraParams {
+		if strings.TrimSpace(name) == "" {
+			return ErrInvalidExtraParams
+		}
+	}
+	switch c.RetryJitter {
+	case "", RetryJitterNone, RetryJitterFull, RetryJitterEqual:
+	default:
+		return ErrInvalidRetryJitter
+	}
+	if c.MinSleep < 0 {
+		return ErrInvalidMinSleep
+	}
+	switch c.CatchUpPolicy {
+	case "", CatchUpImmediate, CatchUpSkip, CatchUpResync:
+	default:
+		return ErrInvalidCatchUpPolicy
+	}
+	switch c.LongMessagePolicy {
+	case "", LongMessageError:
+	case LongMessageTruncate, LongMessageSplit:
+		if len(c.Entities) > 0 && string(c.Entities) != "null" {
+			return ErrLongMessageEntities
+		}
+	default:
+		return ErrInvalidLongMessagePolicy
+	}
+	switch c.TreatOkFalseAs {
+	case "", OkFalseError, OkFalseWarn, OkFalseRetry:
+	default:
+		return ErrInvalidTreatOkFalseAs
+	}
+	if c.MaxResponseBody < 0 {
+		return ErrInvalidMaxResponseBody
+	}
+	if c.DiscardResponseBody && (c.EditAfter > 0 || c.CleanupOnStop || c.TrackClockSkew) {
+		return ErrDiscardResponseBody
+	}
+	if c.WarmupRequests < 0 || c.WarmupDuration < 0 {
+		return ErrInvalidWarmup
+	}
+	if c.StartDelay < 0 {
+		return ErrInvalidStartDelay
+	}
+	if c.Interval < 0 {
+		return ErrInvalidInterval
+	}
+	// Interval действует только в обычном режиме: ramp-тест и targetLatency задают темп сами
+	if c.Interval == 0 && !c.AllowUnbounded && c.RampTest == nil && c.TargetLatency == 0 {
+		return ErrUnboundedInterval
+	}
+	if c.RequestTimeout < 0 {
+		return ErrInvalidRequestTimeout
+	}
+	if (c.RequestTimeoutMin != 0 || c.RequestTimeoutMax != 0) &&
+		(c.RequestTimeoutMin <= 0 || c.RequestTimeoutMax <= 0 || c.RequestTimeoutMin > c.RequestTimeoutMax) {
+		return ErrInvalidRequestTimeoutRange
+	}
+	if c.MaxBandwidth < 0 {
+		return ErrInvalidMaxBandwidth
+	}
+	if c.MaxRequestsPerConn < 0 {
+		return ErrInvalidMaxRequestsPerConn
+	}
+	if c.TLSExpiryWarning < 0 {
+		return ErrInvalidTLSExpiryWarning
+	}
+	if c.FailureInjectionRate < 0 || c.FailureInjectionRate > 1 {
+		return ErrInvalidFailureInjectionRate
+	}
+	if c.VerboseFirstN < 0 {
+		return ErrInvalidVerboseFirstN
+	}
+	if c.SummaryInterval < 0 {
+		return ErrInvalidSummaryInterval
+	}
+	if c.StatsInterval < 0 {
+		return ErrInvalidStatsInterval
+	}
+	if c.TargetLatency < 0 || c.MaxWorkers < 0 || c.AdjustInterval < 0 {
+		return ErrInvalidLatencyTarget
+	}
+	if c.TargetLatency > 0 && c.RampTest != nil {
+		return ErrConflictingModes
+	}
+	if c.EditAfter < 0 || (c.EditAfter > 0 && (c.UploadFile != "" || c.RampTest != nil || c.TargetLatency > 0)) {
+		return ErrInvalidEditMode
+	}
+	switch c.LogOverflowPolicy {
+	case "", "drop-newest", "drop-oldest", "block":
+	default:
+		return ErrInvalidLogOverflowPolicy
+	}
+	for _, level := range []string{c.StdoutLogLevel, c.SSELogLevel} {
+		switch level {
+		case "", LogLevelInfo, LogLevelWarn, LogLevelError, LogLevelOff:
+		default:
+			return ErrInvalidLogLevel
+		}
+	}
+	if c.QuietHoursStart != "" || c.QuietHoursEnd != "" {
+		if _, err := time.Parse(QuietHoursLayout, c.QuietHoursStart); err != nil {
+			return ErrInvalidQuietHours
+		}
+		if _, err := time.Parse(QuietHoursLayout, c.QuietHoursEnd); err != nil {
+			return ErrInvalidQuietHours
+		}
+	}
+	if c.ReportFile != "" {
+		if info, err := os.Stat(filepath.Dir(c.ReportFile)); err != nil || !info.IsDir() {
+			return fmt.Errorf("%w: нет каталога %s", ErrInvalidReportFile, filepath.Dir(c.ReportFile))
+		}
+	}
+	if c.UploadFile != "" {
+		info, err := os.Stat(c.UploadFile)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUploadFileUnavailable, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("%w: %s является каталогом", ErrUploadFileUnavailable, c.UploadFile)
+		}
+		if c.SigningSecret != "" {
+			return ErrUploadSigning
+		}
+	}
 	return nil
 }
 
+// linkPreviewOptions поля объекта LinkPreviewOptions Bot API
+type linkPreviewOptions struct {
+	IsDisabled       *bool   `json:"is_disabled"`
+	URL              *string `json:"url"`
+	PreferSmallMedia *bool   `json:"prefer_small_media"`
+	PreferLargeMedia *bool   `json:"prefer_large_media"`
+	ShowAboveText    *bool   `json:"show_above_text"`
+}
+
+// validLinkPreviewOptions проверяет, что raw — объект LinkPreviewOptions без лишних полей
+// и с правильными типами; null и массив не принимаются
+func validLinkPreviewOptions(raw json.RawMessage) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var opts *linkPreviewOptions
+	if err := dec.Decode(&opts); err != nil {
+		return err
+	}
+	if opts == nil {
+		return errors.New("ожидается JSON-объект")
+	}
+	if opts.PreferSmallMedia != nil && *opts.PreferSmallMedia && opts.PreferLargeMedia != nil && *opts.PreferLargeMedia {
+		return errors.New("prefer_small_media и prefer_large_media нельзя включать одновременно")
+	}
+	return nil
+}
+
+// Targets возвращает список чатов для отправки: ChatID и дополнительные ChatIDs без пустых и повторов
+func (c *Config) Targets() []string {
+	seen := make(map[string]bool, len(c.ChatIDs)+1)
+	targets := make([]string, 0, len(c.ChatIDs)+1)
+	for _, id := range append([]string{c.ChatID}, c.ChatIDs...) {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		targets = append(targets, id)
+	}
+	return targets
+}
+
+// Tokens возвращает токены ботов для ротации: BotToken и дополнительные BotTokens без пустых и повторов
+func (c *Config) Tokens() []string {
+	seen := make(map[string]bool, len(c.BotTokens)+1)
+	tokens := make([]string, 0, len(c.BotTokens)+1)
+	for _, token := range append([]string{c.BotToken}, c.BotTokens...) {
+		if token == "" || seen[token] {
+			continue
+		}
+		seen[token] = true
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// defaultAbortCodes коды ошибок Telegram, останавливающие запуск, если AbortOnErrorCodes не задан:
+// 401 — неверный или отозванный токен
+var defaultAbortCodes = []int{401}
+
+// AbortCodes коды ошибок Telegram (error_code), при которых запуск останавливается:
+// AbortOnErrorCodes или, если поле не задано (null), только 401; пустой список отключает остановку
+func (c *Config) AbortCodes() []int {
+	if c.AbortOnErrorCodes == nil {
+		return defaultAbortCodes
+	}
+	return c.AbortOnErrorCodes
+}
+
+// defaultChatSpacing промежуток между сообщениями в один чат, если ChatSpacing не задан:
+// Telegram допускает около одного сообщения в секунду на чат
+const defaultChatSpacing = time.Second
+
+// MinChatSpacing минимальный промежуток между отправками в один чат: ChatSpacing или, если
+// не задан (0), одна секунда; отрицательное значение отключает ограничение (возвращается 0)
+func (c *Config) MinChatSpacing() time.Duration {
+	switch {
+	case c.ChatSpacing < 0:
+		return 0
+	case c.ChatSpacing == 0:
+		return defaultChatSpacing
+	}
+	return c.ChatSpacing
+}
+
+// Location пояс отметки времени в сообщениях: IANA-имя Timezone или, если не задано, локальный
+func (c *Config) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTimezone, err)
+	}
+	return loc, nil
+}
+
+// redactedValue подставляется вместо секретов в Redacted
+const redactedValue = "<redacted>"
+
+// Redacted возвращает копию конфигурации без секретов: токенов ботов, секрета подписи и пароля прокси
+func (c *Config) Redacted() *Config {
+	r := *c
+	if r.BotToken != "" {
+		r.BotToken = redactedValue
+	}
+	if len(r.BotTokens) > 0 {
+		r.BotTokens = make([]string, len(c.BotTokens))
+		for i := range r.BotTokens {
+			r.BotTokens[i] = redactedValue
+		}
+	}
+	if r.SigningSecret != "" {
+		r.SigningSecret = redactedValue
+	}
+	r.ProxyURL = RedactProxyURL(r.ProxyURL)
+	if len(r.Proxies) > 0 {
+		r.Proxies = make([]string, len(c.Proxies))
+		for i, proxy := range c.Proxies {
+			r.Proxies[i] = RedactProxyURL(proxy)
+		}
+	}
+	return &r
+}
+
+// validateProxyURL проверяет адрес прокси; пустой — прямое подключение
+func validateProxyURL(proxy string) error {
+	if proxy == "" {
+		return nil
+	}
+	// url.Parse принимает "http://" без хоста, а такой прокси ломает каждый запрос
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProxyURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%w: %s", ErrInvalidProxyURL, proxy)
+	}
+	return nil
+}
+
+// RedactProxyURL скрывает пароль в адресе прокси
+func RedactProxyURL(proxy string) string {
+	if u, err := url.Parse(proxy); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return proxy
+}
+
 // Default возвращает конфигурацию с значениями по умолчанию
 func Default() *Config {
 	return &Config{
-		Timeout:  60 * time.Second,
-		Interval: 3 * time.Second,
+		Timeout:        60 * time.Second,
+		Interval:       3 * time.Second,
+		CleanupTimeout: 30 * time.Second,
 	}
 }
-
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Decode читает JSON-конфигурацию поверх cfg. Неизвестные поля отклоняются, а ошибки
+// типов и синтаксиса переводятся в понятные сообщения с именем поля
+func Decode(r io.Reader, cfg *Config) error {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("%w: %s", ErrConfigDecode, decodeHint(err))
+	}
+	if dec.More() {
+		return fmt.Errorf("%w: после объекта конфигурации есть лишние данные", ErrConfigDecode)
+	}
+	return nil
+}
+
+// decodeHint описывает ошибку json.Decoder с указанием поля и ожидаемого значения
+func decodeHint(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return fmt.Sprintf("ожидается JSON-объект, получено %s", typeErr.Value)
+		}
+		return fmt.Sprintf("поле %q: ожидается %s, получено %s", typeErr.Field, expectedValue(typeErr.Type), typeErr.Value)
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("синтаксическая ошибка на позиции %d: %v", syntaxErr.Offset, syntaxErr)
+	case errors.Is(err, io.EOF):
+		return "пустое тело запроса"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "JSON оборван"
+	}
+	// Для неизвестного поля encoding/json не даёт типизированной ошибки
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return fmt.Sprintf("неизвестное поле %s — проверьте имя или обновите сервер", field)
+	}
+	return err.Error()
+}
+
+// expectedValue описывает ожидаемое значение поля типа t
+func expectedValue(t reflect.Type) string {
+	if t == durationType {
+		return "длительность в наносекундах (число, например 3000000000 — это 3 с)"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "строка"
+	case reflect.Bool:
+		return "true или false"
+	case reflect.Int, reflect.Int64:
+		return "целое число"
+	case reflect.Float64:
+		return "число"
+	case reflect.Slice:
+		return "массив"
+	case reflect.Struct, reflect.Map:
+		return "объект"
+	}
+	return t.String()
+}
@@ -3,7 +3,59 @@ package config
 import "errors"
 
 var (
-	ErrChatIDRequired   = errors.New("chat ID обязателен для указания")
-	ErrBotTokenRequired = errors.New("токен бота обязателен для указания")
+	ErrChatIDRequired              = errors.New("chat ID обязателен для указания")
+	ErrBotTokenRequired            = errors.New("токен бота обязателен для указания")
+	ErrInvalidPreSend              = errors.New("preSendField должен быть botToken или text, preSendTimeout не может быть отрицательным")
+	ErrInvalidProxyURL             = errors.New("прокси URL должен содержать схему и хост, например http://host:port")
+	ErrInvalidProxyStickiness      = errors.New("proxyStickiness не может быть отрицательным и требует непустого списка proxies")
+	ErrInvalidDNSServer            = errors.New("DNS сервер задаётся как host:port, например 1.1.1.1:53")
+	ErrInvalidLocalAddr            = errors.New("локальный адрес задаётся как IP или IP:port, например 192.168.1.10 или [2001:db8::1]:0")
+	ErrInvalidDoHEndpoint          = errors.New("DoH endpoint должен быть https URL, например https://1.1.1.1/dns-query")
+	ErrInvalidChatSelection        = errors.New("выбор чата должен быть roundRobin, random или sequentialExhaust")
+	ErrInvalidAliasFile            = errors.New("файл псевдонимов чатов должен быть JSON-объектом {\"псевдоним\": \"chat ID\"}")
+	ErrUnknownChatAlias            = errors.New("псевдоним чата не найден в файле псевдонимов")
+	ErrInvalidRequestEncoding      = errors.New("кодировка запроса должна быть form, json или multipart")
+	ErrUploadFileUnavailable       = errors.New("файл для загрузки недоступен")
+	ErrUploadSigning               = errors.New("uploadFile не совместим с signingSecret: потоковая загрузка файла не подписывается")
+	ErrInvalidReportFile           = errors.New("некорректный путь отчёта")
+	ErrEntitiesNotArray            = errors.New("entities должен быть JSON-массивом")
+	ErrInvalidLinkPreviewOptions   = errors.New("linkPreviewOptions должен быть объектом LinkPreviewOptions (is_disabled, url, prefer_small_media, prefer_large_media, show_above_text)")
+	ErrInvalidLogLevel             = errors.New("stdoutLogLevel и sseLogLevel должны быть info, warn, error или off")
+	ErrInvalidLogOverflowPolicy    = errors.New("политика переполнения лога должна быть drop-newest, drop-oldest или block")
+	ErrInvalidMessages             = errors.New("у каждого сообщения должен быть текст и неотрицательный вес, хотя бы один вес больше нуля")
+	ErrInvalidRampTest             = errors.New("ramp-тест: startRPS, stepRPS и stepDuration должны быть положительными, maxFailureRate — от 0 до 100")
+	ErrInvalidLatencyTarget        = errors.New("targetLatency, maxWorkers и adjustInterval не могут быть отрицательными")
+	ErrConflictingModes            = errors.New("rampTest и targetLatency нельзя включать одновременно")
+	ErrInvalidEditMode             = errors.New("editAfter не может быть отрицательным и не совместим с uploadFile, rampTest и targetLatency")
+	ErrInvalidQuietHours           = errors.New("тихие часы задаются парой значений в формате ЧЧ:ММ")
+	ErrInvalidRetryBudget          = errors.New("maxRetries, retryRate, retryBurst, headerTimeout, headerTimeoutStep и retryMaxBackoff не могут быть отрицательными")
+	ErrInvalidPerCycleBudget       = errors.New("perCycleBudget не может быть отрицательным")
+	ErrInvalidRetryJitter          = errors.New("джиттер повторов должен быть none, full или equal")
+	ErrInvalidMinSleep             = errors.New("minSleep не может быть отрицательным")
+	ErrInvalidCatchUpPolicy        = errors.New("catchUpPolicy должен быть immediate, skip или resync")
+	ErrInvalidLongMessagePolicy    = errors.New("longMessagePolicy должен быть error, truncate или split")
+	ErrLongMessageEntities         = errors.New("longMessagePolicy truncate и split не совместимы с entities: смещения сущностей нельзя пересчитать по частям")
+	ErrInvalidTreatOkFalseAs       = errors.New("treatOkFalseAs должен быть error, warn или retry")
+	ErrInvalidRetryOnStatus        = errors.New("retryOnStatus должен содержать HTTP-статусы от 100 до 599")
+	ErrInvalidAbortOnErrorCodes    = errors.New("abortOnErrorCodes должен содержать коды ошибок от 100 до 599")
+	ErrInvalidExtraParams          = errors.New("extraParams: имя параметра не может быть пустым")
+	ErrInvalidTimezone             = errors.New("timezone должен быть именем пояса IANA, например Europe/Moscow")
+	ErrInvalidMessageThreadID      = errors.New("messageThreadID должен быть положительным числом")
+	ErrInvalidReplyToMessageID     = errors.New("replyToMessageID должен быть положительным числом")
+	ErrDiscardResponseBody         = errors.New("discardResponseBody не совместим с editAfter, cleanupOnStop и trackClockSkew: им нужны ID и дата сообщения из ответа")
+	ErrInvalidMaxResponseBody      = errors.New("maxResponseBody не может быть отрицательным")
+	ErrInvalidMaxRequestsPerConn   = errors.New("maxRequestsPerConn не может быть отрицательным")
+	ErrInvalidMaxBandwidth         = errors.New("maxBandwidth не может быть отрицательным")
+	ErrInvalidRequestTimeout       = errors.New("requestTimeout не может быть отрицательным")
+	ErrInvalidRequestTimeoutRange  = errors.New("requestTimeoutMin и requestTimeoutMax задаются вместе, положительными и min не больше max")
+	ErrInvalidWarmup               = errors.New("warmupRequests и warmupDuration не могут быть отрицательными")
+	ErrInvalidStartDelay           = errors.New("startDelay не может быть отрицательным")
+	ErrInvalidInterval             = errors.New("interval не может быть отрицательным")
+	ErrUnboundedInterval           = errors.New("interval 0 отправляет запросы подряд без пауз и быстро упирается в лимиты Telegram; задайте интервал или подтвердите режим allowUnbounded")
+	ErrInvalidTLSExpiryWarning     = errors.New("tlsExpiryWarning не может быть отрицательным")
+	ErrInvalidVerboseFirstN        = errors.New("verboseFirstN не может быть отрицательным")
+	ErrInvalidSummaryInterval      = errors.New("summaryInterval не может быть отрицательным")
+	ErrInvalidStatsInterval        = errors.New("statsInterval не может быть отрицательным")
+	ErrConfigDecode                = errors.New("ошибка декодирования JSON")
+	ErrInvalidFailureInjectionRate = errors.New("failureInjectionRate должен быть от 0 до 1")
 )
-
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TargetSpec полный адрес сообщения: чат, тред (тема супергруппы) и сообщение, на которое
+// отвечаем. Поля проверяются вместе, потому что ответ внутри треда должен ссылаться на
+// сообщение из того же треда
+type TargetSpec struct {
+	ChatID           string `json:"chatID"`
+	MessageThreadID  string `json:"messageThreadID,omitempty"`
+	ReplyToMessageID string `json:"replyToMessageID,omitempty"`
+}
+
+// Target адрес сообщения для чата chatID с тредом и ответом из конфигурации
+func (c *Config) Target(chatID string) TargetSpec {
+	return TargetSpec{ChatID: chatID, MessageThreadID: c.MessageThreadID, ReplyToMessageID: c.ReplyToMessageID}
+}
+
+// Validate проверяет, что ID треда и сообщения для ответа, если заданы, — положительные числа
+func (t TargetSpec) Validate() error {
+	if t.MessageThreadID != "" && !positiveID(t.MessageThreadID) {
+		return fmt.Errorf("%w: %q", ErrInvalidMessageThreadID, t.MessageThreadID)
+	}
+	if t.ReplyToMessageID != "" && !positiveID(t.ReplyToMessageID) {
+		return fmt.Errorf("%w: %q", ErrInvalidReplyToMessageID, t.ReplyToMessageID)
+	}
+	return nil
+}
+
+// positiveID сообщает, что строка — положительный числовой ID
+func positiveID(s string) bool {
+	id, err := strconv.ParseInt(s, 10, 64)
+	return err == nil && id > 0
+}
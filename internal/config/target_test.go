@@ -0,0 +1,51 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTargetSpecValidate(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{value: "", valid: true},
+		{value: "123", valid: true},
+		{value: "-1", valid: false},
+		{value: "abc", valid: false},
+		{value: "1.5", valid: false},
+	}
+	fields := []struct {
+		name   string
+		target func(string) TargetSpec
+		err    error
+	}{
+		{
+			name:   "messageThreadID",
+			target: func(v string) TargetSpec { return TargetSpec{ChatID: "1", MessageThreadID: v} },
+			err:    ErrInvalidMessageThreadID,
+		},
+		{
+			name:   "replyToMessageID",
+			target: func(v string) TargetSpec { return TargetSpec{ChatID: "1", ReplyToMessageID: v} },
+			err:    ErrInvalidReplyToMessageID,
+		},
+	}
+	for _, f := range fields {
+		for _, tt := range tests {
+			t.Run(f.name+"="+tt.value, func(t *testing.T) {
+				err := f.target(tt.value).Validate()
+				if tt.valid {
+					if err != nil {
+						t.Fatalf("Validate(%q) = %v, ожидалось без ошибки", tt.value, err)
+					}
+					return
+				}
+				if !errors.Is(err, f.err) {
+					t.Fatalf("Validate(%q) = %v, ожидалось %v", tt.value, err, f.err)
+				}
+			})
+		}
+	}
+}
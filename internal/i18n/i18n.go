@@ -0,0 +1,65 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// Lang язык сообщений лога
+type Lang string
+
+// Поддерживаемые языки
+const (
+	RU Lang = "ru"
+	EN Lang = "en"
+)
+
+// ErrUnsupportedLang возвращается при попытке выбрать неизвестный язык
+var ErrUnsupportedLang = errors.New("неподдерживаемый язык (допустимо: ru, en)")
+
+// message содержит перевод одного сообщения на все языки
+type message struct {
+	ru string
+	en string
+}
+
+var current atomic.Value
+
+func init() {
+	current.Store(RU)
+}
+
+// SetLang выбирает язык сообщений лога
+func SetLang(lang Lang) error {
+	switch lang {
+	case RU, EN:
+		current.Store(lang)
+		return nil
+	default:
+		return ErrUnsupportedLang
+	}
+}
+
+// Current возвращает выбранный язык
+func Current() Lang {
+	return current.Load().(Lang)
+}
+
+// T возвращает сообщение по ключу на выбранном языке, подставляя аргументы как fmt.Sprintf.
+// Для неизвестного ключа возвращается сам ключ
+func T(key string, args ...interface{}) string {
+	msg, ok := catalog[key]
+	if !ok {
+		return key
+	}
+
+	format := msg.ru
+	if Current() == EN && msg.en != "" {
+		format = msg.en
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
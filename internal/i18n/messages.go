@@ -0,0 +1,243 @@
+package i18n
+
+// catalog содержит все сообщения лога, ключ — идентификатор сообщения.
+// При добавлении сообщения заполняйте оба языка
+var catalog = map[string]message{
+	// Настройка клиента и ответы API
+	"client.dialStart":           {ru: "🔌 Dialer: начало подключения к %s (%s)", en: "🔌 Dialer: connecting to %s (%s)"},
+	"client.dialError":           {ru: "🔌 Dialer: ошибка подключения к %s за %v: %v", en: "🔌 Dialer: failed to connect to %s in %v: %v"},
+	"client.dialDone":            {ru: "🔌 Dialer: подключено к %s за %v (local: %s)", en: "🔌 Dialer: connected to %s in %v (local: %s)"},
+	"client.keepAliveDisabled":   {ru: "🔄 Keep-Alive отключён: каждый запрос будет использовать новое соединение", en: "🔄 Keep-Alive disabled: every request will use a new connection"},
+	"client.proxyConnect":        {ru: "🔀 Proxy CONNECT: ответ от прокси %s -> статус %d %s", en: "🔀 Proxy CONNECT: proxy %s responded -> status %d %s"},
+	"client.proxyConnectFailed":  {ru: "🔀 Proxy CONNECT: туннель не установлен, код %d", en: "🔀 Proxy CONNECT: tunnel not established, code %d"},
+	"client.proxyConfigured":     {ru: "🔀 Прокси настроен: %s (схема: %s, хост: %s)", en: "🔀 Proxy configured: %s (scheme: %s, host: %s)"},
+	"client.proxyAuth":           {ru: "🔀 Прокси аутентификация: пользователь '%s'", en: "🔀 Proxy authentication: user '%s'"},
+	"client.proxyNone":           {ru: "Прокси не используется (прямое подключение)", en: "No proxy (direct connection)"},
+	"client.proxyPool":           {ru: "🔀 Пул прокси: %d адресов, смена после %d запросов подряд (proxyURL не используется)", en: "🔀 Proxy pool: %d addresses, rotating after %d consecutive requests (proxyURL is not used)"},
+	"client.proxyDirect":         {ru: "(прямое подключение)", en: "(direct)"},
+	"client.proxySwitch":         {ru: "🔀 Смена прокси: %s → %s после %d запросов", en: "🔀 Proxy switch: %s → %s after %d requests"},
+	"client.encoding":            {ru: "Кодировка тела запроса: %s", en: "Request body encoding: %s"},
+	"client.resolverSystem":      {ru: "🔍 DNS: системный резолвер", en: "🔍 DNS: system resolver"},
+	"client.resolverServer":      {ru: "🔍 DNS: сервер %s", en: "🔍 DNS: server %s"},
+	"client.resolverDoH":         {ru: "🔍 DNS: DNS-over-HTTPS через %s", en: "🔍 DNS: DNS-over-HTTPS via %s"},
+	"client.resolverProxyNote":   {ru: "🔍 DNS: через прокси резолвером разрешается только адрес прокси, api.telegram.org разрешает прокси", en: "🔍 DNS: with a proxy only the proxy host is resolved locally; api.telegram.org is resolved by the proxy"},
+	"client.localAddr":           {ru: "🔌 Исходящие соединения с локального адреса %s", en: "🔌 Outgoing connections bound to local address %s"},
+	"client.forcedReconnect":     {ru: "🔁 Предел %d запросов на соединение: запрос уходит с Connection: close, следующий откроет новое соединение", en: "🔁 Limit of %d requests per connection: request sent with Connection: close, the next one opens a new connection"},
+	"client.created":             {ru: "HTTP клиент создан. Timeout: %v, DialTimeout: 30s, TLSHandshake: 15s, ResponseHeader: 30s", en: "HTTP client created. Timeout: %v, DialTimeout: 30s, TLSHandshake: 15s, ResponseHeader: 30s"},
+	"client.messageIDParseError": {ru: "Не удалось разобрать message_id из ответа: %v", en: "Failed to parse message_id from response: %v"},
+	"client.messageEdited":       {ru: "Сообщение изменено, message_id=%d", en: "Message edited, message_id=%d"},
+	"client.webhookNone":         {ru: "Вебхук не установлен, ожидающих обновлений: %d", en: "No webhook set, pending updates: %d"},
+	"client.webhookSet":          {ru: "Установлен вебхук %s, ожидающих обновлений: %d — getUpdates не работает, пока он не снят", en: "Webhook %s is set, pending updates: %d — getUpdates will not work until it is removed"},
+	"client.webhookDeleted":      {ru: "Вебхук снят (удалить ожидающие обновления: %t)", en: "Webhook removed (drop pending updates: %t)"},
+	"client.messageSent":         {ru: "Сообщение отправлено, message_id=%d", en: "Message sent, message_id=%d"},
+	"client.uploadOpenError":     {ru: "Не удалось открыть файл %s: %v", en: "Failed to open file %s: %v"},
+	"client.uploadPrepared":      {ru: "Загрузка файла %s: %d байт, тело запроса %d байт", en: "Uploading file %s: %d bytes, request body %d bytes"},
+	"client.uploadDone":          {ru: "Файл отправлен: %d байт за %v", en: "File uploaded: %d bytes in %v"},
+	"client.prepareRequest":      {ru: "Подготовка запроса %s к %s", en: "Preparing %s request to %s"},
+	"client.encodeError":         {ru: "Ошибка кодирования параметров (%s): %v", en: "Failed to encode parameters (%s): %v"},
+	"client.createRequestError":  {ru: "Ошибка создания запроса: %v", en: "Failed to create request: %v"},
+	"client.doRequest":           {ru: "Выполнение HTTP запроса...", en: "Executing HTTP request..."},
+	"client.requestError":        {ru: "HTTP запрос ошибка за %v: %v", en: "HTTP request failed in %v: %v"},
+	"client.reasonDeadline":      {ru: "Причина: превышен таймаут контекста", en: "Reason: context deadline exceeded"},
+	"client.reasonCanceled":      {ru: "Причина: контекст отменён", en: "Reason: context canceled"},
+	"client.urlError":            {ru: "URL Error детали - Op: %s, Timeout: %v", en: "URL Error details - Op: %s, Timeout: %v"},
+	"client.innerError":          {ru: "Внутренняя ошибка: %v", en: "Inner error: %v"},
+	"client.responseReceived":    {ru: "📥 Ответ получен. Статус: %d, Время: %v, ConnReused: %v", en: "📥 Response received. Status: %d, Time: %v, ConnReused: %v"},
+	"client.responseHeaders":     {ru: "📥 Response Headers: Content-Length=%s, Content-Type=%s", en: "📥 Response Headers: Content-Length=%s, Content-Type=%s"},
+	"client.readError":           {ru: "Ошибка чтения тела ответа за %v: %v", en: "Failed to read response body in %v: %v"},
+	"client.bodyDecoded":         {ru: "Тело ответа распаковано (%s): %d → %d байт", en: "Response body decoded (%s): %d → %d bytes"},
+	"client.decodeError":         {ru: "Не удалось распаковать тело ответа (%s), разбираю как есть: %v", en: "Failed to decode response body (%s), parsing as is: %v"},
+	"client.extraParamSkipped":   {ru: "Параметр %s из extraParams пропущен: он уже задан", en: "extraParams entry %s skipped: already set"},
+	"client.bodyDiscarded":       {ru: "Тело ответа вычитано без разбора за %v, размер: %d байт", en: "Response body discarded unparsed in %v, size: %d bytes"},
+	"client.bodyRead":            {ru: "Тело ответа прочитано за %v, размер: %d байт", en: "Response body read in %v, size: %d bytes"},
+	"client.apiError":            {ru: "Telegram API ошибка: status=%d, body=%s", en: "Telegram API error: status=%d, body=%s"},
+	"client.jsonParseError":      {ru: "Не удалось разобрать JSON ответа: %v", en: "Failed to parse response JSON: %v"},
+	"client.okFalse":             {ru: "Telegram API вернул ok=false: %d %s", en: "Telegram API returned ok=false: %d %s"},
+	"client.checkStart":          {ru: "Проверка соединения с %s", en: "Checking connection to %s"},
+	"client.checkError":          {ru: "Проверка соединения не удалась за %v: %v", en: "Connection check failed in %v: %v"},
+	"client.checkDone":           {ru: "Соединение установлено за %v (DNS: %v, TCP: %v, TLS: %v)", en: "Connection established in %v (DNS: %v, TCP: %v, TLS: %v)"},
+	"client.requestDump":         {ru: "Исходящий запрос:\n%s", en: "Outgoing request:\n%s"},
+	"client.dumpBodyOmitted":     {ru: "[тело %d байт не показано]", en: "[body of %d bytes omitted]"},
+	"client.dumpError":           {ru: "Не удалось сформировать дамп запроса: %v", en: "Failed to dump request: %v"},
+	"client.requestSuccess":      {ru: "Запрос успешен. Общее время: %v", en: "Request succeeded. Total time: %v"},
+
+	// Трейсинг HTTP запроса
+	"trace.getConnProxy":      {ru: "📡 GetConn: запрос соединения для %s (через прокси)", en: "📡 GetConn: requesting connection for %s (via proxy)"},
+	"trace.getConn":           {ru: "📡 GetConn: запрос соединения для %s", en: "📡 GetConn: requesting connection for %s"},
+	"trace.dnsStartProxy":     {ru: "🔍 DNS lookup начат для прокси: %s", en: "🔍 DNS lookup started for proxy: %s"},
+	"trace.dnsStart":          {ru: "🔍 DNS lookup начат для: %s", en: "🔍 DNS lookup started for: %s"},
+	"trace.dnsError":          {ru: "🔍 DNS lookup ошибка: %v (за %v)", en: "🔍 DNS lookup error: %v (in %v)"},
+	"trace.dnsDone":           {ru: "🔍 DNS lookup завершён за %v. IP: %v", en: "🔍 DNS lookup done in %v. IP: %v"},
+	"trace.connectStartProxy": {ru: "🔗 TCP соединение с ПРОКСИ начато: %s %s", en: "🔗 TCP connection to PROXY started: %s %s"},
+	"trace.connectStart":      {ru: "🔗 TCP соединение начато: %s %s", en: "🔗 TCP connection started: %s %s"},
+	"trace.connectErrorProxy": {ru: "🔗 TCP соединение с ПРОКСИ ошибка: %v (за %v)", en: "🔗 TCP connection to PROXY error: %v (in %v)"},
+	"trace.connectError":      {ru: "🔗 TCP соединение ошибка: %v (за %v)", en: "🔗 TCP connection error: %v (in %v)"},
+	"trace.connectDoneProxy":  {ru: "🔗 TCP соединение с ПРОКСИ установлено за %v", en: "🔗 TCP connection to PROXY established in %v"},
+	"trace.connectDone":       {ru: "🔗 TCP соединение установлено за %v", en: "🔗 TCP connection established in %v"},
+	"trace.tlsLeaf":           {ru: "🔐 Сертификат сервера: %s, издатель: %s, действует с %s по %s, SAN: %s", en: "🔐 Server certificate: %s, issuer: %s, valid from %s to %s, SAN: %s"},
+	"trace.tlsChainCert":      {ru: "🔐 Цепочка [%d]: %s, издатель: %s, действует с %s по %s", en: "🔐 Chain [%d]: %s, issuer: %s, valid from %s to %s"},
+	"trace.tlsCertExpiring":   {ru: "🔐 Сертификат %s истекает %s (через %v)", en: "🔐 Certificate %s expires %s (in %v)"},
+	"conn.open":               {ru: "🔌 Соединение #%d открыто: %v (локальный %v)", en: "🔌 Connection #%d opened: %v (local %v)"},
+	"conn.reuse":              {ru: "♻️ Соединение #%d к %v переиспользовано: возраст %v, простой %v, запрос %d", en: "♻️ Connection #%d to %v reused: age %v, idle %v, request %d"},
+	"conn.close":              {ru: "✂️ Соединение #%d к %v закрыто: возраст %v, с последнего запроса %v, запросов %d", en: "✂️ Connection #%d to %v closed: age %v, since last request %v, requests %d"},
+	"trace.summary":           {ru: "req#%d %s reused=%t dns=%s conn=%s tls=%s ttfb=%s total=%s status=%s", en: "req#%d %s reused=%t dns=%s conn=%s tls=%s ttfb=%s total=%s status=%s"},
+	"trace.tlsStartProxy":     {ru: "🔐 TLS handshake начат (через прокси-туннель к api.telegram.org)", en: "🔐 TLS handshake started (through proxy tunnel to api.telegram.org)"},
+	"trace.tlsStart":          {ru: "🔐 TLS handshake начат", en: "🔐 TLS handshake started"},
+	"trace.tlsError":          {ru: "🔐 TLS handshake ошибка: %v (за %v)", en: "🔐 TLS handshake error: %v (in %v)"},
+	"trace.tlsDone":           {ru: "🔐 TLS handshake завершён за %v. Версия: %s, Cipher: %s, ServerName: %s", en: "🔐 TLS handshake done in %v. Version: %s, Cipher: %s, ServerName: %s"},
+	"trace.gotConnReused":     {ru: "✅ GotConn: переиспользовано соединение к %s (idle: %v, всего: %v)", en: "✅ GotConn: reused connection to %s (idle: %v, total: %v)"},
+	"trace.gotConnNew":        {ru: "✅ GotConn: новое соединение к %s (всего: %v)", en: "✅ GotConn: new connection to %s (total: %v)"},
+	"trace.wroteHeaders":      {ru: "📤 HTTP заголовки отправлены", en: "📤 HTTP headers sent"},
+	"trace.wroteRequestError": {ru: "📤 Ошибка записи запроса: %v", en: "📤 Failed to write request: %v"},
+	"trace.wroteRequest":      {ru: "📤 Запрос полностью отправлен, ожидание ответа...", en: "📤 Request fully sent, waiting for response..."},
+	"trace.firstByte":         {ru: "📥 Первый байт ответа получен за %v (TTFB)", en: "📥 First response byte received in %v (TTFB)"},
+
+	// Отправитель
+	"sender.started":                 {ru: "========== ЗАПУСК ОТПРАВКИ ==========", en: "========== SENDING STARTED =========="},
+	"sender.intervalMode":            {ru: "Режим отправки: по одному запросу, не чаще раза в %v (до %.2f запросов/с)", en: "Sending model: one request at a time, at most once per %v (up to %.2f requests/s)"},
+	"sender.unboundedMode":           {ru: "⚠️ Режим отправки: запросы подряд без пауз (interval 0, allowUnbounded) — темп ограничен только ответами Telegram и легко упирается в 429", en: "⚠️ Sending model: back-to-back requests without pauses (interval 0, allowUnbounded) — pace is bounded only by Telegram replies and easily hits 429"},
+	"sender.config":                  {ru: "Конфигурация: Таймаут=%v, Интервал=%v", en: "Configuration: Timeout=%v, Interval=%v"},
+	"sender.chatID":                  {ru: "Chat ID: %s", en: "Chat ID: %s"},
+	"sender.uploadMode":              {ru: "Режим загрузки файла: %s (sendDocument)", en: "File upload mode: %s (sendDocument)"},
+	"sender.entitiesMode":            {ru: "Форматирование через entities (parse_mode не передаётся)", en: "Formatting via entities (parse_mode omitted)"},
+	"sender.quietHours":              {ru: "Тихие часы: %s–%s (disable_notification)", en: "Quiet hours: %s–%s (disable_notification)"},
+	"sender.notificationMode":        {ru: "Уведомление: %s", en: "Notification: %s"},
+	"sender.notifySilent":            {ru: "без звука", en: "silent"},
+	"sender.notifySound":             {ru: "обычное", en: "normal"},
+	"sender.plainTextFallback":       {ru: "Telegram не разобрал разметку (%s) — повторяем без форматирования", en: "Telegram could not parse the markup (%s) — retrying as plain text"},
+	"sender.chatSelected":            {ru: "Чат для запроса: %s (%s)", en: "Target chat: %s (%s)"},
+	"sender.headerDeadline":          {ru: "Попытка %d: ожидание заголовков ответа не дольше %v (до %s)", en: "Attempt %d: waiting for response headers at most %v (until %s)"},
+	"sender.headerTimeout":           {ru: "Попытка %d: заголовки ответа не получены за %v, попытка прервана", en: "Attempt %d: no response headers within %v, attempt aborted"},
+	"sender.rateLimitChat":           {ru: "429 для чата %s: лимит чата, пауза %v только для него, остальные чаты продолжают", en: "429 for chat %s: per-chat limit, pausing only this chat for %v, other chats continue"},
+	"sender.rateLimitGlobal":         {ru: "429 от %d чатов за %v — вероятно, общий лимит бота: пауза %v для всех чатов", en: "429 from %d chats within %v, likely the bot-wide limit: pausing all chats for %v"},
+	"sender.rateLimitWait":           {ru: "Чат %s на паузе после 429: ожидание %v (лимит: %s)", en: "Chat %s is paused after 429: waiting %v (limit: %s)"},
+	"sender.rateLimitSkip":           {ru: "Чат %s на паузе после 429 ещё %v, выбираем следующий", en: "Chat %s is paused after 429 for another %v, picking the next one"},
+	"sender.bandwidthThrottle":       {ru: "Ограничение трафика %d Б/с: отправка отложена на %v", en: "Bandwidth cap %d B/s: delaying the send by %v"},
+	"sender.clientTimeout":           {ru: "Сработал таймаут HTTP-клиента timeout (%v) на попытке %d: одна попытка не уложилась — увеличьте timeout", en: "HTTP client timeout (%v) fired on attempt %d: a single attempt took too long, raise timeout"},
+	"sender.cycleBudgetExhausted":    {ru: "Бюджет цикла perCycleBudget (%v) исчерпан на попытке %d: отправка вместе с повторами прекращена и засчитана ошибкой", en: "Cycle budget perCycleBudget (%v) exhausted on attempt %d: the send and its retries are abandoned as a failure"},
+	"sender.contextTimeout":          {ru: "Истёк срок запроса requestTimeout (%v) на попытке %d: запрос вместе с повторами не уложился — увеличьте requestTimeout", en: "Request deadline requestTimeout (%v) expired on attempt %d: the request with its retries took too long, raise requestTimeout"},
+	"sender.tokenSelected":           {ru: "Токен бота %s:***", en: "Bot token %s:***"},
+	"sender.requestTimeoutPicked":    {ru: "Срок запроса выбран из диапазона: %v", en: "Request timeout picked from range: %v"},
+	"sender.chatSpacingWait":         {ru: "Чат %s: ожидание %v — между сообщениями в один чат не меньше %v", en: "Chat %s: waiting %v — messages to one chat are at least %v apart"},
+	"sender.discardBodyMode":         {ru: "Тело успешных ответов не читается в память (discardResponseBody): ID сообщений неизвестны", en: "Successful response bodies are discarded (discardResponseBody): message IDs are unknown"},
+	"sender.startDelay":              {ru: "Задержка старта %v: первый запрос в %s", en: "Start delay %v: first request at %s"},
+	"sender.startDelayDone":          {ru: "Задержка старта истекла, первый запрос в %s", en: "Start delay elapsed, first request at %s"},
+	"sender.warmupStart":             {ru: "Прогрев: %d запросов и %v с начала запуска не входят в основную статистику", en: "Warmup: %d requests and %v from the start are excluded from the main stats"},
+	"sender.warmupDone":              {ru: "Прогрев завершён (%d запросов за %v), начинается измерение", en: "Warmup finished (%d requests in %v), measurement starts"},
+	"sender.clockSkew":               {ru: "Время отправки по Telegram: %s, расхождение часов: %v", en: "Telegram send time: %s, clock skew: %v"},
+	"sender.statusNoRetry":           {ru: "Статус %d не входит в retryOnStatus — запрос не повторяется", en: "Status %d is not in retryOnStatus — not retrying"},
+	"sender.targetFieldDropped":      {ru: "Telegram отклонил %s для чата %s (%s) — поле больше не передаётся, отправляем без него", en: "Telegram rejected %s for chat %s (%s) — dropping the field and sending without it"},
+	"sender.abortOnError":            {ru: "Ошибка Telegram %d (%s) входит в abortOnErrorCodes — запуск остановлен", en: "Telegram error %d (%s) is in abortOnErrorCodes — stopping the run"},
+	"sender.goroutinePanic":          {ru: "Паника в горутине отправителя, запуск остановлен: %v", en: "Sender goroutine panicked, run stopped: %v"},
+	"sender.retry":                   {ru: "Повтор %d/%d через %v", en: "Retry %d/%d in %v"},
+	"sender.injectionMode":           {ru: "[INJECTED] Включена инъекция отказов: %.1f%% запросов помечаются ошибкой без отправки", en: "[INJECTED] Failure injection enabled: %.1f%% of requests are marked failed without sending"},
+	"sender.injectedFailure":         {ru: "[INJECTED] Синтетический отказ, запрос не отправлен", en: "[INJECTED] Synthetic failure, request not sent"},
+	"sender.okFalseTolerated":        {ru: "Ответ ok=false (%d %s) засчитан успехом: treatOkFalseAs=warn", en: "ok=false response (%d %s) counted as success: treatOkFalseAs=warn"},
+	"sender.clientErrorNoRetry":      {ru: "Ошибка 4xx постоянна (неверный токен, чат или параметры) — запрос не повторяется", en: "4xx error is permanent (bad token, chat or parameters) — not retrying"},
+	"sender.retryBudgetExhausted":    {ru: "Бюджет повторов исчерпан — запрос считается неуспешным", en: "Retry budget exhausted — counting the request as failed"},
+	"sender.editWaiting":             {ru: "Изменение сообщения %d через %v", en: "Editing message %d in %v"},
+	"sender.editSuccess":             {ru: "✅ Сообщение %d изменено за %v", en: "✅ Message %d edited in %v"},
+	"sender.editError":               {ru: "❌ Не удалось изменить сообщение %d за %v: %v", en: "❌ Failed to edit message %d in %v: %v"},
+	"sender.editMode":                {ru: "Режим отправки с изменением: editMessageText через %v после отправки", en: "Send-and-edit mode: editMessageText %v after each send"},
+	"sender.aliasResolved":           {ru: "Псевдоним чата %s → %s", en: "Chat alias %s → %s"},
+	"sender.aliasUnknown":            {ru: "Псевдоним чата %s не найден в файле псевдонимов, используется как есть", en: "Chat alias %s not found in the alias file, used as is"},
+	"sender.aliasFileError":          {ru: "Не удалось прочитать файл псевдонимов, цели используются как есть: %v", en: "Failed to read the alias file, targets used as is: %v"},
+	"sender.summary":                 {ru: "📊 Сводка: запросов %d, успешно %d, ошибок %d; за период %.2f запр/с, в среднем %.1f мс", en: "📊 Summary: %d requests, %d succeeded, %d failed; last period %.2f req/s, avg %.1f ms"},
+	"sender.preSendDone":             {ru: "Команда перед отправкой выполнена за %v, вывод %d байт", en: "Pre-send command finished in %v, %d bytes of output"},
+	"sender.preSendError":            {ru: "Команда перед отправкой не выполнена: %v; stderr: %s", en: "Pre-send command failed: %v; stderr: %s"},
+	"sender.canaryStart":             {ru: "🐤 Канареечное сообщение в чат %s перед запуском", en: "🐤 Canary message to chat %s before the run"},
+	"sender.canaryPassed":            {ru: "🐤 Канареечное сообщение отправлено за %v, начинаем запуск", en: "🐤 Canary message sent in %v, starting the run"},
+	"sender.canaryFailed":            {ru: "🐤 Канареечное сообщение не отправлено за %v: %v — запуск отменён", en: "🐤 Canary message failed after %v: %v — run aborted"},
+	"sender.businessConnectionError": {ru: "Telegram отклонил business_connection_id %s: %s. Нужен бот, подключённый к бизнес-аккаунту, и действующий ID подключения", en: "Telegram rejected business_connection_id %s: %s. A bot connected to a business account and a valid connection ID are required"},
+	"sender.chatMigrated":            {ru: "Чат %s преобразован в супергруппу, новый ID: %s — повторяем отправку", en: "Chat %s migrated to a supergroup, new ID: %s — retrying"},
+	"sender.intervalChanged":         {ru: "Интервал изменён: %v", en: "Interval changed: %v"},
+	"sender.messagePicked":           {ru: "Выбран вариант сообщения #%d (вес %g)", en: "Picked message variant #%d (weight %g)"},
+	"sender.rampConfig":              {ru: "Ramp-тест: старт %g RPS, шаг %g RPS каждые %v, пороги: ошибки ≤ %.1f%%, p95 ≤ %v", en: "Ramp test: start %g RPS, step %g RPS every %v, thresholds: failures ≤ %.1f%%, p95 ≤ %v"},
+	"sender.rampStep":                {ru: "Ступень %g RPS: отправлено %d, ошибок %d (%.1f%%), p95 %v", en: "Step %g RPS: sent %d, failed %d (%.1f%%), p95 %v"},
+	"sender.rampDone":                {ru: "Ramp-тест завершён: максимальная устойчивая нагрузка %g RPS", en: "Ramp test finished: max sustainable load %g RPS"},
+	"sender.rampNoGood":              {ru: "Ramp-тест завершён: уже начальная ступень превысила пороги", en: "Ramp test finished: even the starting step exceeded the thresholds"},
+	"sender.latencyConfig":           {ru: "Режим целевой задержки: p95 ≈ %v, до %d воркеров, корректировка каждые %v", en: "Latency target mode: p95 ≈ %v, up to %d workers, adjusting every %v"},
+	"sender.latencyAdjust":           {ru: "p95 %v (цель %v) по %d запросам: воркеров %d → %d", en: "p95 %v (target %v) over %d requests: workers %d → %d"},
+	"sender.verboseEnded":            {ru: "Подробный лог первых %d запросов завершён, дальше — только итоги, предупреждения и ошибки", en: "Verbose logging of the first %d requests is over; only summaries, warnings and errors from now on"},
+	"sender.maxRequests":             {ru: "Лимит запросов: %d", en: "Request limit: %d"},
+	"sender.maxRequestsReached":      {ru: "Достигнут лимит запросов (%d), остановка", en: "Request limit reached (%d), stopping"},
+	"sender.probeDisabled":           {ru: "Keep-alive проба не используется: Keep-Alive отключён", en: "Keep-alive probe unused: Keep-Alive is disabled"},
+	"sender.probeEnabled":            {ru: "Keep-alive проба каждые %v во время простоя", en: "Keep-alive probe every %v while idle"},
+	"sender.proxy":                   {ru: "Прокси: %s", en: "Proxy: %s"},
+	"sender.proxyNone":               {ru: "не используется", en: "not used"},
+	"sender.requestHeader":           {ru: "---------- Запрос #%d ----------", en: "---------- Request #%d ----------"},
+	"sender.requestStart":            {ru: "Время начала: %s", en: "Start time: %s"},
+	"sender.contextCreated":          {ru: "Контекст создан с таймаутом %v", en: "Context created with timeout %v"},
+	"sender.duplicateMessage":        {ru: "Текст сообщения совпадает с ранее отправленным в этом запуске", en: "Message text duplicates one already sent in this run"},
+	"sender.messageGenerated":        {ru: "Сообщение сгенерировано (%d байт)", en: "Message generated (%d bytes)"},
+	"sender.resultError":             {ru: "РЕЗУЛЬТАТ #%d: ОШИБКА за %v", en: "RESULT #%d: FAILURE in %v"},
+	"sender.errorDetails":            {ru: "Детали ошибки: %v", en: "Error details: %v"},
+	"sender.parentContext":           {ru: "Контекст родителя: %v", en: "Parent context: %v"},
+	"sender.resultSuccess":           {ru: "РЕЗУЛЬТАТ #%d: УСПЕХ за %v", en: "RESULT #%d: SUCCESS in %v"},
+	"sender.waiting":                 {ru: "Ожидание %v до следующего запроса...", en: "Waiting %v until next request..."},
+	"sender.stopSignal":              {ru: "Получен сигнал остановки", en: "Stop signal received"},
+	"sender.catchUpSkip":             {ru: "Запрос занял больше интервала (%v > %v): пропущено слотов расписания: %d, следующий запрос через %v (catchUpPolicy=skip)", en: "Request took longer than interval (%v > %v): %d schedule slots skipped, next request in %v (catchUpPolicy=skip)"},
+	"sender.catchUpResync":           {ru: "Запрос занял больше интервала (%v > %v): расписание начато заново, следующий запрос через %v (catchUpPolicy=resync)", en: "Request took longer than interval (%v > %v): schedule restarted, next request in %v (catchUpPolicy=resync)"},
+	"sender.messageTooLong":          {ru: "Текст длиннее предела (%d > %d символов): отправляется как есть, Telegram отклонит запрос (longMessagePolicy=error)", en: "Text exceeds limit (%d > %d characters): sent as is, Telegram will reject it (longMessagePolicy=error)"},
+	"sender.messageTruncated":        {ru: "Текст длиной %d обрезан до %d символов (предел %d, longMessagePolicy=truncate)", en: "Text of length %d truncated to %d characters (limit %d, longMessagePolicy=truncate)"},
+	"sender.messageSplit":            {ru: "Текст длиной %d (предел %d) разбит на частей: %d (longMessagePolicy=split)", en: "Text of length %d (limit %d) split into %d parts (longMessagePolicy=split)"},
+	"sender.minSleep":                {ru: "Пауза до следующего запроса увеличена до minSleep (%v) вместо %v", en: "Pause before next request raised to minSleep (%v) instead of %v"},
+	"sender.overInterval":            {ru: "Запрос занял больше интервала (%v > %v), следующий запрос сразу", en: "Request took longer than interval (%v > %v), next request immediately"},
+	"sender.probeError":              {ru: "💓 Keep-alive проба: ошибка за %v: %v", en: "💓 Keep-alive probe: failed in %v: %v"},
+	"sender.probeSuccess":            {ru: "💓 Keep-alive проба: успех за %v", en: "💓 Keep-alive probe: succeeded in %v"},
+	"sender.cleanupNothing":          {ru: "Очистка: нет сообщений для удаления", en: "Cleanup: no messages to delete"},
+	"sender.cleanupStart":            {ru: "Очистка: удаление %d сообщений (бюджет %v)", en: "Cleanup: deleting %d messages (budget %v)"},
+	"sender.cleanupFailed":           {ru: "Очистка: не удалось удалить message_id=%d: %v", en: "Cleanup: failed to delete message_id=%d: %v"},
+	"sender.cleanupDone":             {ru: "Очистка завершена: удалено %d, ошибок %d, пропущено по таймауту %d", en: "Cleanup done: deleted %d, failed %d, skipped by timeout %d"},
+
+	// Сервер
+	"server.metricsListening":   {ru: "Метрики Prometheus доступны на http://localhost%s/metrics", en: "Prometheus metrics served on http://localhost%s/metrics"},
+	"server.listening":          {ru: "Сервер запущен на http://localhost%s", en: "Server listening on http://localhost%s"},
+	"server.configUpdated":      {ru: "Конфигурация обновлена", en: "Configuration updated"},
+	"server.configAppliedLive":  {ru: "Конфигурация применена к работающему запуску со следующего запроса", en: "Configuration applied to the running run from the next request"},
+	"server.configRestart":      {ru: "Изменённые поля %s применятся только после перезапуска", en: "Changed fields %s take effect only after restart"},
+	"server.restarting":         {ru: "Перезапуск: останавливаем текущий запуск", en: "Restart: stopping the current run"},
+	"server.restartWaited":      {ru: "Предыдущий запуск завершился за %v, запускаем новый", en: "Previous run finished in %v, starting a new one"},
+	"server.started":            {ru: "Отправка запущена", en: "Sending started"},
+	"server.statsEvent":         {ru: "Статистика: %d запросов, %d успешно, %d ошибок, %.2f запр/с", en: "Stats: %d requests, %d succeeded, %d failed, %.2f req/s"},
+	"server.reportWritten":      {ru: "Отчёт запуска записан в %s", en: "Run report written to %s"},
+	"server.reportError":        {ru: "Не удалось записать отчёт запуска: %v", en: "Failed to write the run report: %v"},
+	"server.shuttingDown":       {ru: "Получен сигнал завершения, останавливаем запуск", en: "Shutdown signal received, stopping the run"},
+	"server.shutdownTimeout":    {ru: "Запуск не успел завершиться до выхода", en: "The run did not finish before exit"},
+	"server.botWebhookDeleted":  {ru: "Вебхук бота %s снят", en: "Webhook of bot %s removed"},
+	"server.botWebhookError":    {ru: "Вебхук бота %s: %v", en: "Webhook of bot %s: %v"},
+	"server.presetSaved":        {ru: "Текущая конфигурация сохранена как пресет %q", en: "Current configuration saved as preset %q"},
+	"server.presetApplied":      {ru: "Применён пресет %q", en: "Preset %q applied"},
+	"server.presetsFile":        {ru: "Пресеты конфигурации хранятся в файле %s", en: "Configuration presets stored in %s"},
+	"server.senderPanic":        {ru: "Аварийное завершение отправителя (паника): %v", en: "Sender crashed (panic): %v"},
+	"server.statsdSink":         {ru: "Метрики запросов отправляются в StatsD %s (префикс %s)", en: "Sending request metrics to StatsD %s (prefix %s)"},
+	"server.droppedLogs":        {ru: "Пропущено %d записей лога из-за переполнения канала (всего: %d)", en: "Dropped %d log entries due to a full channel (total: %d)"},
+	"server.webhookSent":        {ru: "Итоги запуска отправлены в webhook %s (статус %d)", en: "Run summary posted to webhook %s (status %d)"},
+	"server.webhookError":       {ru: "Не удалось отправить итоги запуска в webhook: %v", en: "Failed to post run summary to webhook: %v"},
+	"server.proxyTestOK":        {ru: "Проверка прокси: соединение установлено за %v", en: "Proxy test: connection established in %v"},
+	"server.proxyTestFailed":    {ru: "Проверка прокси не пройдена: %v", en: "Proxy test failed: %v"},
+	"server.autoStopUnobserved": {ru: "Автоостановка: за %v к логам не подключился ни один клиент", en: "Auto-stop: no client has watched the logs for %v"},
+	"server.logSinkError":       {ru: "Ошибка записи в файл лога: %v", en: "Failed to write log file: %v"},
+	"server.logFile":            {ru: "Логи пишутся в файл %s (ротация при %d МБ, копий: %d)", en: "Writing logs to %s (rotate at %d MB, backups: %d)"},
+	"server.runStopped":         {ru: "Запуск остановлен (причина: %s)", en: "Run stopped (reason: %s)"},
+	"server.runCompleted":       {ru: "Запуск завершился сам (причина: %s)", en: "Run completed on its own (reason: %s)"},
+	"server.stopped":            {ru: "Отправка остановлена", en: "Sending stopped"},
+	"server.stopWaited":         {ru: "Отправитель завершился за %v", en: "Sender exited in %v"},
+	"server.stopGraceOver":      {ru: "Отправитель не завершился за %v, остановка без ожидания", en: "Sender did not exit within %v, stopping without waiting"},
+
+	// Бенчмарк
+	"bench.started":       {ru: "Бенчмарк: %d запросов, допустимая доля ошибок %.1f%%", en: "Benchmark: %d requests, max failure rate %.1f%%"},
+	"bench.runLabel":      {ru: "Метка запуска: %s", en: "Run label: %s"},
+	"bench.runID":         {ru: "ID запуска: %s", en: "Run ID: %s"},
+	"bench.summary":       {ru: "Итоги: запросов %d, успешных %d, ошибок %d (%.1f%%), причина остановки: %s", en: "Summary: requests %d, succeeded %d, failed %d (%.1f%%), stop reason: %s"},
+	"bench.phase":         {ru: "  %-8s n=%-5d avg=%.1fms p50=%.1fms p95=%.1fms p99=%.1fms max=%.1fms", en: "  %-8s n=%-5d avg=%.1fms p50=%.1fms p95=%.1fms p99=%.1fms max=%.1fms"},
+	"bench.failed":        {ru: "Доля ошибок %.1f%% превышает порог %.1f%%", en: "Failure rate %.1f%% exceeds threshold %.1f%%"},
+	"bench.passed":        {ru: "Доля ошибок в пределах порога", en: "Failure rate within threshold"},
+	"bench.noProxies":     {ru: "Для сравнения прокси задайте список proxies в конфигурации", en: "Set the proxies list in the config to compare proxies"},
+	"bench.proxyStarted":  {ru: "Сравнение прокси: %d прокси по %d запросов", en: "Proxy comparison: %d proxies, %d requests each"},
+	"bench.proxyRun":      {ru: "[%d/%d] Прокси %s", en: "[%d/%d] Proxy %s"},
+	"bench.proxyDirect":   {ru: "(прямое подключение)", en: "(direct)"},
+	"bench.proxyHeader":   {ru: "Прокси                                    запросов  успешно        avg        p95", en: "Proxy                                     requests  success        avg        p95"},
+	"bench.proxyRow":      {ru: "%-40s  %8d  %6.1f%%  %7.1fms  %7.1fms", en: "%-40s  %8d  %6.1f%%  %7.1fms  %7.1fms"},
+	"bench.proxyRowError": {ru: "%-40s  не проверен: %s", en: "%-40s  not tested: %s"},
+}
@@ -0,0 +1,88 @@
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Writer пишет в файл с ротацией по размеру: когда очередная запись не помещается
+// в maxSize, текущий файл становится path.1, старые копии сдвигаются до path.<backups>
+type Writer struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+// Open открывает (или создаёт) файл для дозаписи. maxSize <= 0 отключает ротацию
+func Open(path string, maxSize int64, backups int) (*Writer, error) {
+	w := &Writer{path: path, maxSize: maxSize, backups: backups}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write дописывает p в файл, предварительно выполняя ротацию при необходимости
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close закрывает текущий файл
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// open открывает файл по основному пути и запоминает его текущий размер
+func (w *Writer) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("открытие файла лога: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("открытие файла лога: %w", err)
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// rotate сдвигает копии, переименовывает текущий файл и открывает новый
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("ротация файла лога: %w", err)
+	}
+
+	if w.backups <= 0 {
+		if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("ротация файла лога: %w", err)
+		}
+		return w.open()
+	}
+
+	os.Remove(fmt.Sprintf("%s.%d", w.path, w.backups))
+	for i := w.backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1))
+	}
+	if err := os.Rename(w.path, w.path+".1"); err != nil {
+		return fmt.Errorf("ротация файла лога: %w", err)
+	}
+	return w.open()
+}
@@ -0,0 +1,25 @@
+package sender
+
+import (
+	"errors"
+	"slices"
+
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// abortOnError останавливает весь запуск, если запрос завершился ошибкой Telegram с кодом
+// из AbortOnErrorCodes (по умолчанию 401 — токен неверен или отозван): повторять такие
+// запросы бессмысленно. Причина остановки — abortOnError; в лог пишется один раз
+func (s *Sender) abortOnError(requestNum int, err error) {
+	var apiErr *telegram.APIError
+	if s.abort == nil || !errors.As(err, &apiErr) || !slices.Contains(s.conf().AbortCodes(), apiErr.ErrorCode) {
+		return
+	}
+	if !s.aborted.CompareAndSwap(false, true) {
+		return
+	}
+	s.logReq(requestNum, "error", CategoryRun, i18n.T("sender.abortOnError", apiErr.ErrorCode, apiErr.Description),
+		map[string]interface{}{"errorCode": apiErr.ErrorCode, "reason": StopAbortOnError})
+	s.abort(StopCause(StopAbortOnError))
+}
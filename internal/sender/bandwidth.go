@@ -0,0 +1,84 @@
+package sender
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// bandwidthLimiter общий для всех воркеров счётчик трафика (MaxBandwidth): запрос расходует
+// фактически переданные байты (тела и заголовки в обе стороны), кредит восполняется со
+// скоростью rate байт в секунду, но не больше чем на секунду вперёд. Размер запроса заранее
+// неизвестен, поэтому отправка ждёт, пока не будет погашен долг предыдущих
+type bandwidthLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	credit float64
+	last   time.Time
+}
+
+// newBandwidthLimiter создаёт ограничитель с полным кредитом на секунду трафика
+func newBandwidthLimiter(rate int64) *bandwidthLimiter {
+	return &bandwidthLimiter{rate: float64(rate), credit: float64(rate), last: time.Now()}
+}
+
+// refill начисляет кредит за прошедшее время; вызывается под mu
+func (b *bandwidthLimiter) refill() {
+	now := time.Now()
+	b.credit = math.Min(b.rate, b.credit+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// consume списывает переданные байты; кредит может уйти в минус
+func (b *bandwidthLimiter) consume(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	b.credit -= float64(n)
+}
+
+// delay возвращает, сколько ждать до погашения долга; 0 — можно отправлять
+func (b *bandwidthLimiter) delay() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	if b.credit >= 0 {
+		return 0
+	}
+	return time.Duration(-b.credit / b.rate * float64(time.Second))
+}
+
+// awaitBandwidth ждёт, пока трафик предыдущих запросов не уложится в MaxBandwidth, и
+// логирует задержку. false — контекст отменён раньше
+func (s *Sender) awaitBandwidth(ctx context.Context, requestNum int) bool {
+	if s.bandwidth == nil {
+		return true
+	}
+	delay := s.bandwidth.delay()
+	if delay <= 0 {
+		return true
+	}
+	s.logReq(requestNum, "info", CategoryWait, i18n.T("sender.bandwidthThrottle", int64(s.bandwidth.rate), delay),
+		map[string]interface{}{"maxBandwidth": int64(s.bandwidth.rate), "delayMs": durationMs(delay)})
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// recordBandwidth учитывает трафик попытки запроса
+func (s *Sender) recordBandwidth(result *telegram.SendResult) {
+	if s.bandwidth == nil || result == nil {
+		return
+	}
+	t := result.Timings
+	s.bandwidth.consume(t.BytesSent + t.HeaderBytesSent + t.BytesReceived + t.HeaderBytesReceived)
+}
@@ -0,0 +1,44 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// ErrCanaryFailed канареечное сообщение не отправлено; запуск не начинается
+var ErrCanaryFailed = errors.New("канареечное сообщение не отправлено")
+
+// Canary отправляет одно сообщение в первый чат до запуска и возвращает ошибку, если оно
+// не прошло. Одна попытка, без повторов и инъекции отказов; в статистику запуска не попадает
+func (s *Sender) Canary(ctx context.Context) error {
+	cfg := s.conf()
+	if s.chats == nil {
+		s.chats = newChatPicker(cfg.ChatSelection, s.resolveTargets(cfg))
+	}
+	chatID := s.chats.targets[0]
+	s.log("info", CategoryRun, i18n.T("sender.canaryStart", chatID))
+
+	canaryCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+	start := time.Now()
+	result, err := s.send(s.requestContext(canaryCtx, 0), 0, chatID, start)
+	d := time.Since(start)
+
+	fields := map[string]interface{}{"chatID": chatID, "success": err == nil, "durationMs": durationMs(d), "canary": true}
+	if err != nil {
+		fields["error"] = err.Error()
+		s.logReq(0, "error", CategoryRun, i18n.T("sender.canaryFailed", d, err), fields)
+		return fmt.Errorf("%w: %v", ErrCanaryFailed, err)
+	}
+	if cfg.CleanupOnStop && result.MessageID != 0 {
+		s.sentMu.Lock()
+		s.sent = append(s.sent, sentMessage{chatID: chatID, messageID: result.MessageID, tokenIdx: s.tokenIndex(0)})
+		s.sentMu.Unlock()
+	}
+	s.logReq(0, "info", CategoryRun, i18n.T("sender.canaryPassed", d), fields)
+	return nil
+}
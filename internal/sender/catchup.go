@@ -0,0 +1,53 @@
+package sender
+
+import (
+	"context"
+	"time"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// catchUp выдерживает паузу перед следующим запросом, когда обычное ожидание интервала не
+// подходит: запрос requestNum, начатый в requestStart, занял elapsed не меньше интервала
+// (отставание обрабатывается по CatchUpPolicy) или до конца интервала осталось меньше MinSleep.
+// Пауза никогда не короче MinSleep. Возвращает false, если контекст отменён во время паузы
+func (s *Sender) catchUp(ctx context.Context, requestNum int, requestStart time.Time, elapsed, interval time.Duration) bool {
+	cfg := s.conf()
+	var sleep time.Duration
+	switch {
+	case elapsed < interval:
+		sleep = interval - elapsed
+	case interval > 0 && cfg.CatchUpPolicy == config.CatchUpSkip:
+		// Следующий запрос — в ближайший слот расписания этого запроса; пропущенные слоты не догоняются
+		missed := int(elapsed / interval)
+		sleep = time.Until(requestStart.Add(time.Duration(missed+1) * interval))
+		s.logReq(requestNum, "warn", CategoryWait, i18n.T("sender.catchUpSkip", elapsed, interval, missed, sleep),
+			map[string]interface{}{"catchUpPolicy": config.CatchUpSkip, "missedSlots": missed, "sleepMs": durationMs(sleep)})
+	case interval > 0 && cfg.CatchUpPolicy == config.CatchUpResync:
+		// Расписание начинается заново: до следующего запроса — полный интервал
+		sleep = interval
+		s.logReq(requestNum, "warn", CategoryWait, i18n.T("sender.catchUpResync", elapsed, interval, sleep),
+			map[string]interface{}{"catchUpPolicy": config.CatchUpResync, "sleepMs": durationMs(sleep)})
+	default:
+		s.logReq(requestNum, "warn", CategoryWait, i18n.T("sender.overInterval", elapsed, interval),
+			map[string]interface{}{"catchUpPolicy": config.CatchUpImmediate})
+	}
+	if sleep < cfg.MinSleep {
+		s.logReq(requestNum, "info", CategoryWait, i18n.T("sender.minSleep", cfg.MinSleep, sleep.Round(time.Millisecond)),
+			map[string]interface{}{"minSleepMs": durationMs(cfg.MinSleep), "sleepMs": durationMs(sleep)})
+		sleep = cfg.MinSleep
+	}
+	if sleep <= 0 {
+		// Проверяем контекст, даже если не ждём
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(sleep)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,29 @@
+package sender
+
+import (
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// clockSkew расхождение часов Telegram с локальными (TrackClockSkew), положительное —
+// часы Telegram спешат. Сообщение создаётся между отправкой запроса и ответом, поэтому
+// сравнивается с серединой последней попытки; date приходит с точностью до секунды
+// и округлена вниз, к ней добавляется полсекунды. Точность оценки — порядка секунды
+func clockSkew(date, received time.Time, attempt time.Duration) time.Duration {
+	local := received.Add(-attempt / 2)
+	return date.Add(time.Second / 2).Sub(local)
+}
+
+// recordClockSkew учитывает расхождение часов по дате успешно отправленного сообщения
+func (s *Sender) recordClockSkew(requestNum int, stats *Stats, result *telegram.SendResult, fields map[string]interface{}) {
+	if !s.conf().TrackClockSkew || result.Date.IsZero() {
+		return
+	}
+	skew := clockSkew(result.Date, time.Now(), result.Timings.Total)
+	stats.recordClockSkew(skew)
+	fields["clockSkewMs"] = durationMs(skew)
+	s.logReq(requestNum, "info", CategoryRequest, i18n.T("sender.clockSkew", result.Date.Format(time.RFC3339), skew.Round(time.Millisecond)),
+		map[string]interface{}{"telegramDate": result.Date.Unix(), "clockSkewMs": durationMs(skew)})
+}
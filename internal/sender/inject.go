@@ -0,0 +1,22 @@
+package sender
+
+import (
+	"errors"
+	"math/rand"
+
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// ErrInjectedFailure ошибка синтетического отказа (FailureInjectionRate): запрос в Telegram не отправлялся
+var ErrInjectedFailure = errors.New("[INJECTED] синтетический отказ")
+
+// injectFailure решает с вероятностью FailureInjectionRate, заменить ли запрос синтетическим отказом
+func (s *Sender) injectFailure(requestNum int) bool {
+	rate := s.conf().FailureInjectionRate
+	if rate <= 0 || rand.Float64() >= rate {
+		return false
+	}
+	s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.injectedFailure"),
+		map[string]interface{}{"injected": true})
+	return true
+}
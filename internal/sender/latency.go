@@ -0,0 +1,163 @@
+package sender
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// CategoryLatency категория записей режима целевой задержки (корректировки числа воркеров)
+const CategoryLatency = "latency"
+
+// Значения режима целевой задержки по умолчанию
+const (
+	defaultMaxWorkers     = 50
+	defaultAdjustInterval = 5 * time.Second
+)
+
+// latencyWindow собирает длительности запросов между корректировками
+type latencyWindow struct {
+	mu        sync.Mutex
+	durations []time.Duration
+}
+
+// add добавляет длительность завершённого запроса
+func (w *latencyWindow) add(d time.Duration) {
+	w.mu.Lock()
+	w.durations = append(w.durations, d)
+	w.mu.Unlock()
+}
+
+// drain возвращает p95 и число запросов окна и очищает его
+func (w *latencyWindow) drain() (time.Duration, int) {
+	w.mu.Lock()
+	durations := w.durations
+	w.durations = nil
+	w.mu.Unlock()
+
+	if len(durations) == 0 {
+		return 0, 0
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	idx := int(0.95*float64(len(durations))+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return durations[idx], len(durations)
+}
+
+// runLatency держит p95 задержки около TargetLatency, меняя число параллельных воркеров.
+// Каждый воркер отправляет запросы подряд без интервала; раз в AdjustInterval число
+// воркеров пересчитывается пропорционально target/p95 (не более чем вдвое за шаг)
+func (s *Sender) runLatency(ctx context.Context) string {
+	cfg := s.conf()
+	target := cfg.TargetLatency
+	maxWorkers := cfg.MaxWorkers
+	if maxWorkers <= 0 {
+		maxWorkers = defaultMaxWorkers
+	}
+	adjustEvery := cfg.AdjustInterval
+	if adjustEvery <= 0 {
+		adjustEvery = defaultAdjustInterval
+	}
+
+	s.log("info", CategoryRun, i18n.T("sender.started"))
+	s.log("info", CategoryLatency, i18n.T("sender.latencyConfig", target, maxWorkers, adjustEvery))
+
+	var (
+		wg         sync.WaitGroup
+		window     latencyWindow
+		requestNum atomic.Int64
+		cancels    []context.CancelFunc
+	)
+	worker := func(workerCtx context.Context) {
+		defer wg.Done()
+		for workerCtx.Err() == nil {
+			num := int(requestNum.Add(1))
+			targetIdx, chatID := s.pickChat(num)
+			start := time.Now()
+
+			// Запрос не прерывается при снятии воркера — только при остановке запуска
+			reqCtx, cancel := s.withRequestTimeout(ctx, num, cfg)
+			reqCtx = s.requestContext(reqCtx, num)
+			result, chatID, err := s.sendFollowingMigration(reqCtx, num, targetIdx, chatID, start)
+			cancel()
+			window.add(s.recordResult(ctx, num, chatID, start, result, err))
+		}
+	}
+	resize := func(n int) {
+		for len(cancels) < n {
+			workerCtx, cancel := context.WithCancel(ctx)
+			cancels = append(cancels, cancel)
+			wg.Add(1)
+			s.goSafe(func() { worker(workerCtx) })
+		}
+		for len(cancels) > n {
+			cancels[len(cancels)-1]()
+			cancels = cancels[:len(cancels)-1]
+		}
+	}
+
+	resize(1)
+	ticker := time.NewTicker(adjustEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			resize(0)
+			wg.Wait()
+			s.log("info", CategoryRun, i18n.T("sender.stopSignal"))
+			return ""
+		case <-ticker.C:
+			p95, count := window.drain()
+			current := len(cancels)
+			next := nextWorkers(current, p95, count, target, maxWorkers)
+			level := "info"
+			if count == 0 {
+				level = "warn"
+			}
+			s.logReq(0, level, CategoryLatency, i18n.T("sender.latencyAdjust", p95, target, count, current, next),
+				map[string]interface{}{
+					"p95Ms":     durationMs(p95),
+					"targetMs":  durationMs(target),
+					"completed": count,
+					"workers":   next,
+				})
+			resize(next)
+		}
+	}
+}
+
+// nextWorkers вычисляет новое число воркеров. Без завершённых запросов (все висят дольше окна)
+// нагрузка уменьшается вдвое; иначе меняется пропорционально target/p95, не более чем вдвое
+func nextWorkers(current int, p95 time.Duration, count int, target time.Duration, maxWorkers int) int {
+	var next int
+	if count == 0 || p95 <= 0 {
+		next = current / 2
+	} else {
+		ratio := float64(target) / float64(p95)
+		if ratio > 2 {
+			ratio = 2
+		}
+		if ratio < 0.5 {
+			ratio = 0.5
+		}
+		next = int(float64(current)*ratio + 0.5)
+		// Пропорция не сдвигает одного воркера вверх: при запасе по задержке добавляем хотя бы одного
+		if ratio > 1.1 && next == current {
+			next++
+		}
+	}
+	if next < 1 {
+		next = 1
+	}
+	if next > maxWorkers {
+		next = maxWorkers
+	}
+	return next
+}
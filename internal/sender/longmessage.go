@@ -0,0 +1,185 @@
+package sender
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// maxMessageLength предел текста sendMessage после разбора разметки. Длина считается
+// в кодовых единицах UTF-16, как смещения entities: для эмодзи это строже лимита Telegram
+const maxMessageLength = 4096
+
+// textCut возможная граница части: байтовое смещение в тексте и видимая длина до него
+type textCut struct {
+	pos     int
+	visible int
+}
+
+// runeUnits длина руны в кодовых единицах UTF-16
+func runeUnits(r rune) int {
+	if n := utf16.RuneLen(r); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// textCuts границы, по которым текст можно разрезать, с видимой длиной до каждой. Для простого
+// текста это любая граница символа. В MarkdownV2 граница не может стоять после «\» и внутри
+// сущности (жирный, курсив, код, ссылка и т. п.), а служебные символы и адрес ссылки не
+// входят в видимую длину — так обрезка не ломает разметку. Последний элемент — конец текста
+func textCuts(text string, markdown bool) []textCut {
+	cuts := []textCut{{}}
+	visible := 0
+	if !markdown {
+		for i, r := range text {
+			if i > 0 {
+				cuts = append(cuts, textCut{pos: i, visible: visible})
+			}
+			visible += runeUnits(r)
+		}
+		return append(cuts, textCut{pos: len(text), visible: visible})
+	}
+
+	var bold, italic, underline, strike, spoiler, code, pre bool
+	link := 0 // 1 — текст ссылки, 2 — адрес в скобках
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		rest := text[i:]
+		switch {
+		case r == '\\' && i+size < len(text):
+			// Экранированный символ виден, но отделять его от «\» нельзя
+			next, nextSize := utf8.DecodeRuneInString(text[i+size:])
+			if link != 2 {
+				visible += runeUnits(next)
+			}
+			i += size + nextSize
+		case strings.HasPrefix(rest, "```") && !code:
+			pre = !pre
+			i += 3
+		case pre:
+			visible += runeUnits(r)
+			i += size
+		case r == '`':
+			code = !code
+			i += size
+		case code:
+			visible += runeUnits(r)
+			i += size
+		case link == 2:
+			if r == ')' {
+				link = 0
+			}
+			i += size
+		case strings.HasPrefix(rest, "__"):
+			underline = !underline
+			i += 2
+		case strings.HasPrefix(rest, "||"):
+			spoiler = !spoiler
+			i += 2
+		case r == '_':
+			italic = !italic
+			i += size
+		case r == '*':
+			bold = !bold
+			i += size
+		case r == '~':
+			strike = !strike
+			i += size
+		case r == '[':
+			link = 1
+			i += size
+		case r == ']' && link == 1 && strings.HasPrefix(rest, "]("):
+			link = 2
+			i += 2
+		default:
+			visible += runeUnits(r)
+			i += size
+		}
+		if !bold && !italic && !underline && !strike && !spoiler && !code && !pre && link == 0 {
+			cuts = append(cuts, textCut{pos: i, visible: visible})
+		}
+	}
+	if last := cuts[len(cuts)-1]; last.pos != len(text) {
+		// Незакрытая сущность: Telegram всё равно отклонит разметку, длину считаем до конца
+		cuts = append(cuts, textCut{pos: len(text), visible: visible})
+	}
+	return cuts
+}
+
+// visibleLength видимая длина текста в кодовых единицах UTF-16
+func visibleLength(text string, markdown bool) int {
+	cuts := textCuts(text, markdown)
+	return cuts[len(cuts)-1].visible
+}
+
+// cutText длина в байтах самой длинной начальной части text, укладывающейся в limit.
+// Предпочитается граница после перевода строки или пробела во второй половине части. Если
+// подходящей границы нет (одна сущность длиннее предела), текст режется по символу — разметка
+// такой части сломается, зато отправка не зациклится
+func cutText(text string, markdown bool, limit int) int {
+	cuts := textCuts(text, markdown)
+	best := 0
+	for j := 1; j < len(cuts) && cuts[j].visible <= limit; j++ {
+		best = j
+	}
+	if cuts[best].pos == 0 {
+		units := 0
+		for i, r := range text {
+			units += runeUnits(r)
+			if units > limit && i > 0 {
+				if markdown && text[i-1] == '\\' && i > 1 {
+					return i - 1
+				}
+				return i
+			}
+		}
+		return len(text)
+	}
+	for j := best; j > 0 && cuts[j].visible >= limit/2; j-- {
+		if c := text[cuts[j].pos-1]; c == '\n' || c == ' ' {
+			return cuts[j].pos
+		}
+	}
+	return cuts[best].pos
+}
+
+// fitMessage применяет LongMessagePolicy к тексту, который вместе с отметкой времени длины
+// reserve не укладывается в maxMessageLength: error — текст как есть (Telegram ответит 400),
+// truncate — одна часть до предела, split — несколько частей, каждая в пределе
+func (s *Sender) fitMessage(requestNum int, text string, opts telegram.MessageOptions, reserve int) []string {
+	markdown := opts.Markdown()
+	length := visibleLength(text, markdown)
+	limit := maxMessageLength - reserve
+	if length <= limit {
+		return []string{text}
+	}
+	policy := s.conf().LongMessagePolicy
+	if policy == "" {
+		policy = config.LongMessageError
+	}
+	fields := map[string]interface{}{"length": length, "limit": maxMessageLength, "longMessagePolicy": policy}
+	switch policy {
+	case config.LongMessageTruncate:
+		part := text[:cutText(text, markdown, limit)]
+		fields["parts"] = 1
+		s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.messageTruncated", length, visibleLength(part, markdown), maxMessageLength), fields)
+		return []string{part}
+	case config.LongMessageSplit:
+		var parts []string
+		for rest := text; rest != ""; {
+			n := cutText(rest, markdown, limit)
+			parts = append(parts, rest[:n])
+			rest = rest[n:]
+		}
+		fields["parts"] = len(parts)
+		s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.messageSplit", length, maxMessageLength, len(parts)), fields)
+		return parts
+	}
+	s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.messageTooLong", length, maxMessageLength), fields)
+	return []string{text}
+}
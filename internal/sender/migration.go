@@ -0,0 +1,29 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// sendFollowingMigration отправляет запрос через sendWithRetry. Если группа стала супергруппой
+// и включён FollowChatMigration, цель targetIdx переключается на новый ID, а запрос повторяется
+// тем же путём — с ожиданием лимитов, повторами и классификацией таймаутов. Возвращает чат,
+// в который ушёл последний запрос
+func (s *Sender) sendFollowingMigration(ctx context.Context, requestNum, targetIdx int, chatID string, requestStart time.Time) (*telegram.SendResult, string, error) {
+	result, err := s.sendWithRetry(ctx, requestNum, chatID, requestStart)
+	var apiErr *telegram.APIError
+	if !s.conf().FollowChatMigration || !errors.As(err, &apiErr) || apiErr.MigrateToChatID == 0 {
+		return result, chatID, err
+	}
+	newChatID := strconv.FormatInt(apiErr.MigrateToChatID, 10)
+	s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.chatMigrated", chatID, newChatID),
+		map[string]interface{}{"chatID": chatID, "migrateToChatID": newChatID})
+	s.chats.replace(targetIdx, newChatID)
+	result, err = s.sendWithRetry(ctx, requestNum, newChatID, requestStart)
+	return result, newChatID, err
+}
@@ -0,0 +1,67 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"SendMsgTestForTG/internal/telegram"
+)
+
+func TestChatMigrationRetriesThroughSendWithRetry(t *testing.T) {
+	var migratedHits atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.FormValue("chat_id") {
+		case "100":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"ok":false,"error_code":400,"description":"Bad Request: group chat was upgraded to a supergroup chat","parameters":{"migrate_to_chat_id":-1001}}`)
+		case "-1001":
+			// Первый запрос в новый чат получает 429: его должен обработать обычный путь повторов
+			if migratedHits.Add(1) == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				fmt.Fprint(w, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"ok":true,"result":{"message_id":1,"date":%d,"chat":{"id":-1001}}}`, time.Now().Unix())
+		default:
+			t.Errorf("неожиданный chat_id %q", r.FormValue("chat_id"))
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := testSenderConfig()
+	cfg.ChatID = "100"
+	cfg.FollowChatMigration = true
+	cfg.MaxRetries = 2
+	cfg.MaxRequests = 1
+	client, err := telegram.NewClient(cfg.Timeout, "", false, "", func(string, string, telegram.LogMeta) {},
+		telegram.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := NewStats()
+	snd := NewSender(cfg, client, stats, drainLogs(t))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if reason := snd.Start(ctx); reason != StopMaxRequests {
+		t.Fatalf("причина остановки %q", reason)
+	}
+
+	snap := stats.Snapshot()
+	if snap.Success != 1 || snap.Retries != 1 {
+		t.Errorf("success=%d retries=%d, ожидалось 1 и 1 (429 после миграции повторён)", snap.Success, snap.Retries)
+	}
+	if got := snd.chats.targets[0]; got != "-1001" {
+		t.Errorf("цель после миграции %q", got)
+	}
+	if _, ok := snap.Chats["-1001"]; !ok {
+		t.Errorf("результат не учтён по новому чату: %v", snap.Chats)
+	}
+}
@@ -0,0 +1,34 @@
+package sender
+
+import (
+	"errors"
+	"time"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// tolerateOkFalse применяет TreatOkFalseAs=warn к итоговой ошибке запроса: ответ ok=false
+// (APIError) пишется в лог предупреждением и засчитывается успехом, чтобы намеренно
+// отклоняемые запросы негативных тестов не попадали в ошибки. Остальные ошибки — как есть
+func (s *Sender) tolerateOkFalse(requestNum int, requestStart time.Time, err error) error {
+	var apiErr *telegram.APIError
+	if s.conf().TreatOkFalseAs != config.OkFalseWarn || !errors.As(err, &apiErr) {
+		return err
+	}
+	s.bucket(requestNum, requestStart).recordOkFalse()
+	s.logReq(requestNum, "warn", CategoryResult, i18n.T("sender.okFalseTolerated", apiErr.ErrorCode, apiErr.Description),
+		map[string]interface{}{"errorCode": apiErr.ErrorCode, "status": apiErr.StatusCode, "treatOkFalseAs": config.OkFalseWarn})
+	return nil
+}
+
+// shouldRetry retryable с учётом TreatOkFalseAs=retry: ответ ok=false повторяется при любом
+// коде, даже постоянном 4xx, в пределах MaxRetries и бюджета повторов
+func shouldRetry(err error, cfg *config.Config) bool {
+	var apiErr *telegram.APIError
+	if cfg.TreatOkFalseAs == config.OkFalseRetry && errors.As(err, &apiErr) {
+		return true
+	}
+	return retryable(err, cfg.RetryOnStatus)
+}
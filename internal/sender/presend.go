@@ -0,0 +1,81 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// defaultPreSendTimeout срок выполнения PreSendCommand, если PreSendTimeout не задан
+const defaultPreSendTimeout = 10 * time.Second
+
+// maxPreSendStderr сколько байт stderr команды попадает в лог при ошибке
+const maxPreSendStderr = 512
+
+// ErrPreSendFailed команда перед отправкой завершилась ошибкой или ничего не вывела; запрос не отправлялся
+var ErrPreSendFailed = errors.New("команда перед отправкой не выполнена")
+
+// preSend выполняет PreSendCommand и возвращает её вывод без пробелов по краям.
+// Вывод может быть секретом (токен), поэтому в лог пишется только его длина
+func (s *Sender) preSend(ctx context.Context, requestNum int, cfg *config.Config) (string, error) {
+	timeout := cfg.PreSendTimeout
+	if timeout <= 0 {
+		timeout = defaultPreSendTimeout
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := shellCommand(cmdCtx, cfg.PreSendCommand)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Дочерние процессы оболочки могут держать вывод открытым и после отмены
+	cmd.WaitDelay = time.Second
+	start := time.Now()
+	err := cmd.Run()
+	d := time.Since(start)
+	out := strings.TrimSpace(stdout.String())
+	if err == nil && out == "" {
+		err = errors.New("пустой вывод")
+	}
+	if err != nil {
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("превышен срок %v", timeout)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > maxPreSendStderr {
+			msg = msg[:maxPreSendStderr]
+		}
+		s.logReq(requestNum, "error", CategoryRequest, i18n.T("sender.preSendError", err, msg),
+			map[string]interface{}{"durationMs": durationMs(d)})
+		return "", fmt.Errorf("%w: %v", ErrPreSendFailed, err)
+	}
+	s.logReq(requestNum, "info", CategoryRequest, i18n.T("sender.preSendDone", d, len(out)),
+		map[string]interface{}{"durationMs": durationMs(d), "bytes": len(out)})
+	return out, nil
+}
+
+// shellCommand запускает строку команды через системную оболочку
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// botToken токен для вспомогательных вызовов (изменение, проба, очистка): последний
+// полученный PreSendCommand, если он подставляется в токен, иначе токен ротации с индексом idx
+func (s *Sender) botToken(cfg *config.Config, idx int) string {
+	if token, ok := s.preSendToken.Load().(string); ok && token != "" && cfg.PreSendCommand != "" && cfg.PreSendField != config.PreSendFieldText {
+		return token
+	}
+	return s.tokens[idx]
+}
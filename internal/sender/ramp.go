@@ -0,0 +1,140 @@
+package sender
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// CategoryRamp категория записей ramp-теста (итоги ступеней и теста)
+const CategoryRamp = "ramp"
+
+// rampStep итоги одной ступени ramp-теста
+type rampStep struct {
+	rps         float64
+	sent        int
+	errors      int
+	failureRate float64
+	p95         time.Duration
+}
+
+// runRamp повышает RPS ступенями, пока ступень укладывается в пороги RampTest, и сообщает
+// последний устойчивый RPS. Запросы ступени отправляются параллельно по таймеру,
+// поэтому RPS не ограничен задержкой одного запроса
+func (s *Sender) runRamp(ctx context.Context) string {
+	cfg := s.conf()
+	rt := cfg.RampTest
+	s.log("info", CategoryRun, i18n.T("sender.started"))
+	s.log("info", CategoryRamp, i18n.T("sender.rampConfig", rt.StartRPS, rt.StepRPS, rt.StepDuration, rt.MaxFailureRate, rt.MaxP95))
+
+	var (
+		lastGood   float64
+		requestNum int
+	)
+	for rps := rt.StartRPS; rt.MaxRPS == 0 || rps <= rt.MaxRPS; rps += rt.StepRPS {
+		step := s.rampStep(ctx, rt, rps, &requestNum)
+		if ctx.Err() != nil {
+			s.log("info", CategoryRun, i18n.T("sender.stopSignal"))
+			if lastGood > 0 {
+				s.rampSummary(lastGood)
+			}
+			return ""
+		}
+
+		ok := step.failureRate <= rt.MaxFailureRate && (rt.MaxP95 == 0 || step.p95 <= rt.MaxP95)
+		level := "info"
+		if !ok {
+			level = "warn"
+		}
+		s.logReq(0, level, CategoryRamp, i18n.T("sender.rampStep", step.rps, step.sent, step.errors, step.failureRate, step.p95),
+			map[string]interface{}{
+				"rps":         step.rps,
+				"sent":        step.sent,
+				"errors":      step.errors,
+				"failureRate": step.failureRate,
+				"p95Ms":       durationMs(step.p95),
+				"passed":      ok,
+			})
+		if !ok {
+			break
+		}
+		lastGood = rps
+	}
+
+	s.rampSummary(lastGood)
+	return StopRampDone
+}
+
+// rampStep отправляет запросы с частотой rps в течение StepDuration и дожидается их завершения
+func (s *Sender) rampStep(ctx context.Context, rt *config.RampTest, rps float64, requestNum *int) rampStep {
+	cfg := s.conf()
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rps))
+	defer ticker.Stop()
+	stepEnd := time.After(rt.StepDuration)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		durations []time.Duration
+		errors    int
+	)
+	send := func(num, targetIdx int, chatID string) {
+		defer wg.Done()
+		start := time.Now()
+		reqCtx, cancel := s.withRequestTimeout(ctx, num, cfg)
+		defer cancel()
+		reqCtx = s.requestContext(reqCtx, num)
+
+		result, chatID, err := s.sendFollowingMigration(reqCtx, num, targetIdx, chatID, start)
+		d := s.recordResult(ctx, num, chatID, start, result, err)
+
+		mu.Lock()
+		durations = append(durations, d)
+		if err != nil {
+			errors++
+		}
+		mu.Unlock()
+	}
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-stepEnd:
+			break loop
+		case <-ticker.C:
+			*requestNum++
+			wg.Add(1)
+			targetIdx, chatID := s.pickChat(*requestNum)
+			num := *requestNum
+			s.goSafe(func() { send(num, targetIdx, chatID) })
+		}
+	}
+	wg.Wait()
+
+	step := rampStep{rps: rps, sent: len(durations), errors: errors}
+	if step.sent > 0 {
+		step.failureRate = float64(errors) / float64(step.sent) * 100
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		idx := int(0.95*float64(len(durations))+0.5) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		step.p95 = durations[idx]
+	}
+	return step
+}
+
+// rampSummary логирует итог ramp-теста
+func (s *Sender) rampSummary(lastGood float64) {
+	if lastGood == 0 {
+		s.logReq(0, "warn", CategoryRamp, i18n.T("sender.rampNoGood"), map[string]interface{}{"maxSustainableRPS": 0})
+		return
+	}
+	s.logReq(0, "info", CategoryRamp, i18n.T("sender.rampDone", lastGood), map[string]interface{}{"maxSustainableRPS": lastGood})
+}
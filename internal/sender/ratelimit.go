@@ -0,0 +1,128 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// globalLimitWindow окно, в котором 429 от разных чатов считаются признаком общего лимита бота
+const globalLimitWindow = time.Second
+
+// Область лимита, на который пришёл 429
+const (
+	RateLimitChat   = "chat"
+	RateLimitGlobal = "global"
+)
+
+// rateLimits хранит паузы после 429: отдельно по чатам и общую. Telegram ограничивает и
+// отправку в один чат (~1 сообщение/с), и бота целиком (~30 сообщений/с); 429 от одного
+// чата не должен останавливать остальные, а 429 от нескольких чатов подряд — общий лимит
+type rateLimits struct {
+	mu     sync.Mutex
+	chats  map[string]time.Time
+	global time.Time
+	// hits недавние 429 (в пределах globalLimitWindow) для определения общего лимита
+	hits []rateLimitHit
+	// slots ближайший свободный момент отправки в чат (ChatSpacing)
+	slots map[string]time.Time
+	// freed отменённые брони в середине очереди к чату: конец брони (UnixNano) → её начало
+	freed map[string]map[int64]time.Time
+}
+
+// rateLimitHit один ответ 429
+type rateLimitHit struct {
+	chatID string
+	at     time.Time
+}
+
+// newRateLimits создаёт пустое состояние лимитов
+func newRateLimits() *rateLimits {
+	return &rateLimits{chats: make(map[string]time.Time), slots: make(map[string]time.Time), freed: make(map[string]map[int64]time.Time)}
+}
+
+// record учитывает 429 от чата с паузой retryAfter и возвращает область лимита и число
+// чатов, получивших 429 за окно. Общим лимит считается, если за окно 429 пришёл от двух и более чатов
+func (l *rateLimits) record(chatID string, retryAfter time.Duration) (string, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	kept := l.hits[:0]
+	for _, hit := range l.hits {
+		if now.Sub(hit.at) < globalLimitWindow {
+			kept = append(kept, hit)
+		}
+	}
+	l.hits = append(kept, rateLimitHit{chatID: chatID, at: now})
+
+	chats := make(map[string]struct{}, len(l.hits))
+	for _, hit := range l.hits {
+		chats[hit.chatID] = struct{}{}
+	}
+	until := now.Add(retryAfter)
+	if len(chats) > 1 {
+		if until.After(l.global) {
+			l.global = until
+		}
+		return RateLimitGlobal, len(chats)
+	}
+	if until.After(l.chats[chatID]) {
+		l.chats[chatID] = until
+	}
+	return RateLimitChat, 1
+}
+
+// delay возвращает оставшуюся паузу для чата (с учётом общей) и её область; 0 — можно отправлять
+func (l *rateLimits) delay(chatID string) (time.Duration, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	chat, global := l.chats[chatID].Sub(now), l.global.Sub(now)
+	switch {
+	case global > 0 && global >= chat:
+		return global, RateLimitGlobal
+	case chat > 0:
+		return chat, RateLimitChat
+	}
+	return 0, ""
+}
+
+// recordRateLimit запоминает паузу из ответа 429 для чата и логирует, какой лимит сработал
+func (s *Sender) recordRateLimit(requestNum int, chatID string, err error) {
+	var apiErr *telegram.APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter <= 0 || errorClass(err) != ErrorClassRateLimit {
+		return
+	}
+	retryAfter := time.Duration(apiErr.RetryAfter) * time.Second
+	scope, chats := s.limitsFor(requestNum).record(chatID, retryAfter)
+	fields := map[string]interface{}{"chatID": chatID, "rateLimitScope": scope, "retryAfterMs": durationMs(retryAfter)}
+	if scope == RateLimitGlobal {
+		s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.rateLimitGlobal", chats, globalLimitWindow, retryAfter), fields)
+		return
+	}
+	s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.rateLimitChat", chatID, retryAfter), fields)
+}
+
+// awaitRateLimit ждёт окончания паузы после 429 для чата. false — контекст отменён раньше
+func (s *Sender) awaitRateLimit(ctx context.Context, requestNum int, chatID string) bool {
+	delay, scope := s.limitsFor(requestNum).delay(chatID)
+	if delay <= 0 {
+		return true
+	}
+	s.logReq(requestNum, "info", CategoryWait, i18n.T("sender.rateLimitWait", chatID, delay, scope),
+		map[string]interface{}{"chatID": chatID, "rateLimitScope": scope, "delayMs": durationMs(delay)})
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,31 @@
+package sender
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// goSafe запускает fn в отдельной горутине запуска и перехватывает её панику: она пишется
+// в лог со стеком, а запуск останавливается с причиной panic. Панику самого Start
+// перехватывает сервер; без этого паника в воркере завершила бы весь процесс
+func (s *Sender) goSafe(fn func()) {
+	go func() {
+		defer s.recoverPanic()
+		fn()
+	}()
+}
+
+// recoverPanic вызывается через defer в горутинах запуска
+func (s *Sender) recoverPanic() {
+	p := recover()
+	if p == nil {
+		return
+	}
+	s.logReq(0, "error", CategoryRun, i18n.T("sender.goroutinePanic", p),
+		map[string]interface{}{"panic": fmt.Sprint(p), "stack": string(debug.Stack()), "reason": StopPanic})
+	if s.abort != nil {
+		s.abort(StopCause(StopPanic))
+	}
+}
@@ -0,0 +1,121 @@
+package sender
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"SendMsgTestForTG/internal/config"
+)
+
+// Report итоговый отчёт запуска для ReportFile: один файл на запуск, который можно разобрать
+// в CI. Конфигурация — без секретов (config.Redacted)
+type Report struct {
+	RunID      string         `json:"runId"`
+	RunLabel   string         `json:"runLabel,omitempty"`
+	Reason     string         `json:"reason"`
+	Started    time.Time      `json:"started"`
+	Finished   time.Time      `json:"finished"`
+	DurationMs float64        `json:"durationMs"`
+	Config     *config.Config `json:"config"`
+	// Stats итоговая статистика, включая ошибки по классам и разбивку по чатам
+	Stats StatsSnapshot `json:"stats"`
+}
+
+// NewReport собирает отчёт запуска из конфигурации, причины остановки и статистики
+func NewReport(runID, reason string, started, finished time.Time, cfg *config.Config, snap StatsSnapshot) Report {
+	return Report{
+		RunID:      runID,
+		RunLabel:   cfg.RunLabel,
+		Reason:     reason,
+		Started:    started,
+		Finished:   finished,
+		DurationMs: durationMs(finished.Sub(started)),
+		Config:     cfg.Redacted(),
+		Stats:      snap,
+	}
+}
+
+// ProxyResult итоги одного прокси в сравнении прокси (-proxy-benchmark)
+type ProxyResult struct {
+	// ProxyURL адрес без пароля; пустой — прямое подключение
+	ProxyURL    string  `json:"proxyURL"`
+	Reason      string  `json:"reason,omitempty"`
+	Total       int     `json:"total"`
+	Success     int     `json:"success"`
+	Errors      int     `json:"errors"`
+	SuccessRate float64 `json:"successRate"`
+	// AvgMs и P95Ms длительность запросов (этап total)
+	AvgMs float64 `json:"avgMs"`
+	P95Ms float64 `json:"p95Ms"`
+	// Error ошибка, из-за которой прокси не проверялся (клиент, канарейка)
+	Error string `json:"error,omitempty"`
+}
+
+// NewProxyResult собирает итоги прокси из статистики его запуска
+func NewProxyResult(proxyURL, reason string, snap StatsSnapshot) ProxyResult {
+	result := ProxyResult{
+		ProxyURL: config.RedactProxyURL(proxyURL),
+		Reason:   reason,
+		Total:    snap.Total,
+		Success:  snap.Success,
+		Errors:   snap.Errors,
+	}
+	if snap.Total > 0 {
+		result.SuccessRate = float64(snap.Success) / float64(snap.Total)
+	}
+	if total, ok := snap.Phases[PhaseTotal]; ok {
+		result.AvgMs = total.AvgMs
+		result.P95Ms = total.P95Ms
+	}
+	return result
+}
+
+// ProxyReport отчёт сравнения прокси для ReportFile: итоги по каждому прокси в порядке Proxies
+type ProxyReport struct {
+	RunID      string         `json:"runId"`
+	RunLabel   string         `json:"runLabel,omitempty"`
+	Started    time.Time      `json:"started"`
+	Finished   time.Time      `json:"finished"`
+	DurationMs float64        `json:"durationMs"`
+	Requests   int            `json:"requests"`
+	Config     *config.Config `json:"config"`
+	Proxies    []ProxyResult  `json:"proxies"`
+}
+
+// WriteReport записывает отчёт в JSON-файл path. Запись идёт во временный файл рядом
+// с path и заменяет его переименованием, чтобы читатель не увидел частично записанный отчёт
+func WriteReport(path string, report Report) error {
+	return writeReportFile(path, report)
+}
+
+// WriteProxyReport записывает отчёт сравнения прокси в JSON-файл path так же, как WriteReport
+func WriteProxyReport(path string, report ProxyReport) error {
+	return writeReportFile(path, report)
+}
+
+// writeReportFile записывает report в path через временный файл
+func writeReportFile(path string, report interface{}) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("отчёт: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("отчёт: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("отчёт: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("отчёт: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("отчёт: %w", err)
+	}
+	return nil
+}
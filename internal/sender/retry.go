@@ -0,0 +1,279 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"slices"
+	"sync"
+	"time"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// retryBackoff пауза перед первым повтором, удваивается с каждой попыткой; 429 ждёт retry_after
+const retryBackoff = 200 * time.Millisecond
+
+// defaultRetryMaxBackoff предел паузы между повторами, если RetryMaxBackoff не задан
+const defaultRetryMaxBackoff = 30 * time.Second
+
+// retryDelay пауза перед повтором attempt: retryBackoff·2^(attempt-1), но не больше maxBackoff.
+// Джиттер по схеме AWS: full — случайно от 0 до паузы, equal — половина паузы плюс случайная половина
+func retryDelay(attempt int, maxBackoff time.Duration, jitter string) time.Duration {
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxBackoff
+	}
+	backoff := maxBackoff
+	if shift := attempt - 1; shift < 32 && retryBackoff<<shift < maxBackoff {
+		backoff = retryBackoff << shift
+	}
+	switch jitter {
+	case config.RetryJitterFull:
+		return time.Duration(rand.Int63n(int64(backoff) + 1))
+	case config.RetryJitterEqual:
+		half := backoff / 2
+		return half + time.Duration(rand.Int63n(int64(backoff-half)+1))
+	}
+	return backoff
+}
+
+// retryBudget общий для всех воркеров token bucket повторов: каждый повтор тратит токен,
+// токены восполняются со скоростью rate в секунду до burst
+type retryBudget struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// RetryBudgetState состояние бюджета повторов для статистики
+type RetryBudgetState struct {
+	Tokens float64 `json:"tokens"`
+	Burst  float64 `json:"burst"`
+	Rate   float64 `json:"rate"`
+}
+
+// newRetryBudget создаёт полный бюджет; burst по умолчанию — токены за секунду (не меньше 1)
+func newRetryBudget(rate float64, burst int) *retryBudget {
+	b := float64(burst)
+	if b <= 0 {
+		b = math.Max(1, math.Ceil(rate))
+	}
+	return &retryBudget{rate: rate, burst: b, tokens: b, last: time.Now()}
+}
+
+// refill начисляет токены за прошедшее время; вызывается под mu
+func (b *retryBudget) refill() {
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// take забирает токен на повтор; false — бюджет исчерпан
+func (b *retryBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// state возвращает текущее состояние бюджета
+func (b *retryBudget) state() RetryBudgetState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	return RetryBudgetState{Tokens: b.tokens, Burst: b.burst, Rate: b.rate}
+}
+
+// Классы ошибок запросов в статистике (errorClasses)
+const (
+	ErrorClassClient    = "4xx"
+	ErrorClassRateLimit = "429"
+	ErrorClassServer    = "5xx"
+	ErrorClassNetwork   = "network"
+	ErrorClassInjected  = "injected"
+	ErrorClassOther     = "other"
+)
+
+// errorClass относит ошибку запроса к классу: ответы API — по коду, ошибки без ответа — к сетевым
+func errorClass(err error) string {
+	if errors.Is(err, ErrInjectedFailure) {
+		return ErrorClassInjected
+	}
+	if errors.Is(err, telegram.ErrResponseTooLarge) || errors.Is(err, ErrPreSendFailed) {
+		return ErrorClassOther
+	}
+	var apiErr *telegram.APIError
+	if !errors.As(err, &apiErr) {
+		return ErrorClassNetwork
+	}
+	switch {
+	case apiErr.ErrorCode == http.StatusTooManyRequests || apiErr.StatusCode == http.StatusTooManyRequests:
+		return ErrorClassRateLimit
+	case apiErr.StatusCode >= http.StatusInternalServerError || apiErr.ErrorCode >= http.StatusInternalServerError:
+		return ErrorClassServer
+	}
+	return ErrorClassClient
+}
+
+// retryable сообщает, имеет ли смысл повторять запрос: сетевые ошибки, 429 и 5xx.
+// Ошибки 4xx (неверный чат, токен, разметка) постоянны, слишком большой ответ повтор не исправит.
+// Непустой retryOn (RetryOnStatus) заменяет правило для ответов API: повторяются только
+// перечисленные HTTP-статусы; сетевые ошибки статуса не имеют и повторяются как прежде
+func retryable(err error, retryOn []int) bool {
+	class := errorClass(err)
+	if status, ok := apiStatus(err); ok && len(retryOn) > 0 && class != ErrorClassInjected && class != ErrorClassOther {
+		return slices.Contains(retryOn, status)
+	}
+	switch class {
+	case ErrorClassNetwork, ErrorClassRateLimit, ErrorClassServer:
+		return true
+	}
+	return false
+}
+
+// apiStatus HTTP-статус ответа API для ошибки запроса; без HTTP-статуса — error_code из тела
+func apiStatus(err error) (int, bool) {
+	var apiErr *telegram.APIError
+	if !errors.As(err, &apiErr) {
+		return 0, false
+	}
+	if apiErr.StatusCode != 0 {
+		return apiErr.StatusCode, true
+	}
+	return apiErr.ErrorCode, apiErr.ErrorCode != 0
+}
+
+// sendWithRetry отправляет сообщение и при временной ошибке повторяет до MaxRetries раз,
+// пока позволяет бюджет повторов. Без бюджета запрос помечается неуспешным. Вся отправка
+// с повторами укладывается в PerCycleBudget: исчерпанный бюджет цикла тоже даёт ошибку
+func (s *Sender) sendWithRetry(ctx context.Context, requestNum int, chatID string, now time.Time) (*telegram.SendResult, error) {
+	// Синтетический отказ не повторяется: доля ошибок в статистике равна FailureInjectionRate
+	if s.injectFailure(requestNum) {
+		return &telegram.SendResult{}, ErrInjectedFailure
+	}
+	cfg := s.conf()
+	ctx, cancel := withCycleBudget(ctx, now, cfg)
+	defer cancel()
+	// Чат (или бот целиком) мог получить 429 в параллельном запросе
+	if !s.awaitRateLimit(ctx, requestNum, chatID) || !s.awaitChatSpacing(ctx, requestNum, chatID) {
+		return &telegram.SendResult{}, ctx.Err()
+	}
+	result, err := s.sendAttempt(ctx, requestNum, chatID, now, 0)
+	maxRetries := cfg.MaxRetries
+	for attempt := 1; attempt <= maxRetries && err != nil && ctx.Err() == nil && shouldRetry(err, cfg); attempt++ {
+		delay := retryDelay(attempt, cfg.RetryMaxBackoff, cfg.RetryJitter)
+		// После 429 ждём паузу этого чата (или общую), записанную в recordRateLimit
+		if limit, _ := s.limitsFor(requestNum).delay(chatID); errorClass(err) == ErrorClassRateLimit && limit > 0 {
+			delay = limit
+		}
+		// Пауза не уложится в бюджет цикла: ждать её бессмысленно, отправка прекращается сразу
+		if !cycleBudgetLeft(now, delay, cfg) {
+			err = s.cycleBudgetExhausted(requestNum, attempt, err)
+			break
+		}
+		if s.retries != nil && !s.retries.take() {
+			s.bucket(requestNum, now).recordRetry(false)
+			s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.retryBudgetExhausted"),
+				map[string]interface{}{"retryDenied": true})
+			break
+		}
+		s.bucket(requestNum, now).recordRetry(true)
+
+		s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.retry", attempt, maxRetries, delay),
+			map[string]interface{}{"attempt": attempt, "delayMs": durationMs(delay), "jitter": cfg.RetryJitter})
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = s.contextTimeout(ctx, requestNum, attempt, err)
+			}
+			return result, err
+		case <-time.After(delay):
+		}
+		if !s.awaitRateLimit(ctx, requestNum, chatID) || !s.awaitChatSpacing(ctx, requestNum, chatID) {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = s.contextTimeout(ctx, requestNum, attempt, err)
+			}
+			return result, err
+		}
+		result, err = s.sendAttempt(ctx, requestNum, chatID, now, attempt)
+	}
+	if maxRetries > 0 && err != nil && ctx.Err() == nil && !shouldRetry(err, cfg) {
+		if status, ok := apiStatus(err); ok && len(cfg.RetryOnStatus) > 0 {
+			s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.statusNoRetry", status),
+				map[string]interface{}{"status": status, "retryOnStatus": cfg.RetryOnStatus})
+		} else if errorClass(err) == ErrorClassClient {
+			s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.clientErrorNoRetry"),
+				map[string]interface{}{"errorClass": ErrorClassClient})
+		}
+	}
+	return result, err
+}
+
+// minHeaderTimeout нижняя граница срока ожидания заголовков при уменьшении на повторах
+const minHeaderTimeout = 500 * time.Millisecond
+
+// errHeaderTimeout причина отмены попытки, не получившей заголовки ответа в срок
+var errHeaderTimeout = errors.New("истёк срок ожидания заголовков ответа")
+
+// headerTimeout срок ожидания заголовков для попытки: HeaderTimeout, уменьшаемый
+// на HeaderTimeoutStep с каждым повтором, но не меньше minHeaderTimeout; 0 — без срока
+func headerTimeout(base, step time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	return max(base-step*time.Duration(attempt), min(base, minHeaderTimeout))
+}
+
+// sendAttempt выполняет одну попытку отправки. С HeaderTimeout попытка отменяется, если
+// первый байт ответа не пришёл в срок — аналог ResponseHeaderTimeout транспорта, но свой
+// для каждой попытки; чтение тела сроком не ограничивается
+func (s *Sender) sendAttempt(ctx context.Context, requestNum int, chatID string, now time.Time, attempt int) (*telegram.SendResult, error) {
+	if !s.awaitBandwidth(ctx, requestNum) {
+		return &telegram.SendResult{}, ctx.Err()
+	}
+	cfg := s.conf()
+	timeout := headerTimeout(cfg.HeaderTimeout, cfg.HeaderTimeoutStep, attempt)
+	if timeout == 0 {
+		result, err := s.send(ctx, requestNum, chatID, now)
+		err = s.classifyTimeout(ctx, requestNum, attempt, err)
+		s.recordBandwidth(result)
+		s.recordRateLimit(requestNum, chatID, err)
+		return result, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	s.logReq(requestNum, "info", CategoryRequest, i18n.T("sender.headerDeadline", attempt+1, timeout, deadline.Format("15:04:05.000")),
+		map[string]interface{}{"attempt": attempt + 1, "headerTimeoutMs": durationMs(timeout)})
+
+	attemptCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	timer := time.AfterFunc(timeout, func() { cancel(errHeaderTimeout) })
+	defer timer.Stop()
+	// Трейс компонуется с трейсом клиента: первый байт ответа снимает срок
+	attemptCtx = httptrace.WithClientTrace(attemptCtx, &httptrace.ClientTrace{
+		GotFirstResponseByte: func() { timer.Stop() },
+	})
+
+	result, err := s.send(attemptCtx, requestNum, chatID, now)
+	err = s.classifyTimeout(ctx, requestNum, attempt, err)
+	s.recordBandwidth(result)
+	s.recordRateLimit(requestNum, chatID, err)
+	if err != nil && errors.Is(context.Cause(attemptCtx), errHeaderTimeout) {
+		s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.headerTimeout", attempt+1, timeout),
+			map[string]interface{}{"attempt": attempt + 1, "headerTimeoutMs": durationMs(timeout)})
+		err = fmt.Errorf("%w (%v): %v", errHeaderTimeout, timeout, err)
+	}
+	return result, err
+}
@@ -2,101 +2,676 @@ package sender
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
 	"SendMsgTestForTG/internal/telegram"
 )
 
 // Sender управляет отправкой сообщений
 type Sender struct {
-	config  *config.Config
+	// cfg текущая конфигурация; значение не изменяется, замена — только целиком через SetConfig
+	cfg     atomic.Pointer[config.Config]
 	client  *telegram.Client
-	logChan chan<- LogEntry
+	stats   *Stats
+	logChan chan LogEntry
+	runID   string
+	chats   *chatPicker
+	retries *retryBudget
+	// tokens токены ботов для ротации (Config.Tokens), limits — паузы после 429 для каждого из них
+	tokens []string
+	limits []*rateLimits
+	// started начало запуска для прогрева (WarmupDuration), warmupDone — прогрев завершён
+	started    time.Time
+	warmupDone atomic.Bool
+	// location пояс отметки времени в сообщениях (AppendTimestamp); nil — без отметки
+	location *time.Location
+	// sinks приёмники результатов запросов (AddResultSink)
+	sinks []ResultSink
+	// abort останавливает запуск изнутри (AbortOnErrorCodes), aborted — остановка уже запрошена
+	abort   context.CancelCauseFunc
+	aborted atomic.Bool
+	// targetDrops поля адреса, отклонённые Telegram, по чатам (TargetFallback)
+	targetMu    sync.Mutex
+	targetDrops map[string]map[string]bool
+	// bandwidth ограничение трафика (MaxBandwidth); nil — без ограничения
+	bandwidth *bandwidthLimiter
+	sent      []sentMessage
+	sentMu    sync.Mutex
+	done      chan struct{}
+	// interval текущий интервал между запросами; меняется на лету через SetInterval
+	interval        atomic.Int64
+	intervalChanged chan struct{}
+	// preSendToken последний токен от PreSendCommand (string) для запросов вне send
+	preSendToken atomic.Value
+}
+
+// sentMessage идентифицирует отправленное сообщение для последующей очистки
+type sentMessage struct {
+	chatID    string
+	messageID int64
+	// tokenIdx индекс токена бота, отправившего сообщение: удалить его может только он
+	tokenIdx int
 }
 
 // LogEntry представляет запись лога
 type LogEntry struct {
-	Time    time.Time `json:"time"`
-	Level   string    `json:"level"`
-	Message string    `json:"message"`
+	ID int64 `json:"id,omitempty"`
+	// RunID идентификатор запуска, к которому относится запись; пусто вне запуска
+	RunID string `json:"runId,omitempty"`
+	// Type тип события жизненного цикла (EventRunStarted и т.д.); пусто для обычных записей
+	Type       string                 `json:"type,omitempty"`
+	Time       time.Time              `json:"time"`
+	Level      string                 `json:"level"`
+	Message    string                 `json:"message"`
+	RequestNum int                    `json:"requestNum,omitempty"`
+	Category   string                 `json:"category,omitempty"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Категории записей лога отправителя
+const (
+	CategoryRun     = "run"
+	CategoryRequest = "request"
+	CategoryResult  = "result"
+	CategoryWait    = "wait"
+	CategoryProbe   = "probe"
+	// CategorySummary периодическая сводка запуска (SummaryInterval)
+	CategorySummary = "summary"
+)
+
+// Типы событий жизненного цикла запуска в потоке логов
+const (
+	EventRunStarted   = "run_started"
+	EventRunStopped   = "run_stopped"
+	EventRunCompleted = "run_completed"
+	EventPaused       = "paused"
+	EventResumed      = "resumed"
+	// EventStats периодический снимок статистики для SSE (StatsInterval); в историю не попадает
+	EventStats = "stats"
+)
+
+// NewLogEntry создаёт запись лога из структурированных полей клиента
+func NewLogEntry(level, message string, meta telegram.LogMeta) LogEntry {
+	return LogEntry{
+		Time:       time.Now(),
+		Level:      level,
+		Message:    message,
+		RequestNum: meta.RequestNum,
+		Category:   meta.Category,
+		Fields:     meta.Fields,
+	}
 }
 
 // NewSender создает новый отправитель
-func NewSender(cfg *config.Config, client *telegram.Client, logChan chan<- LogEntry) *Sender {
-	return &Sender{
-		config:  cfg,
+func NewSender(cfg *config.Config, client *telegram.Client, stats *Stats, logChan chan LogEntry) *Sender {
+	s := &Sender{
 		client:  client,
+		stats:   stats,
 		logChan: logChan,
+		done:    make(chan struct{}),
+		// Буфер 1: уведомление не теряется, если отправитель сейчас не ждёт
+		intervalChanged: make(chan struct{}, 1),
+	}
+	s.cfg.Store(cfg)
+	s.interval.Store(int64(cfg.Interval))
+	stats.setRunLabel(cfg.RunLabel)
+	// Без токенов в конфигурации его выдаёт PreSendCommand
+	s.tokens = cfg.Tokens()
+	if len(s.tokens) == 0 {
+		s.tokens = []string{""}
+	}
+	for range s.tokens {
+		s.limits = append(s.limits, newRateLimits())
+	}
+	if cfg.RetryRate > 0 {
+		s.retries = newRetryBudget(cfg.RetryRate, cfg.RetryBurst)
+		stats.setRetryBudget(s.retries)
+	}
+	if cfg.MaxBandwidth > 0 {
+		s.bandwidth = newBandwidthLimiter(cfg.MaxBandwidth)
+	}
+	if cfg.AppendTimestamp {
+		// Пояс проверен в Validate
+		s.location, _ = cfg.Location()
+	}
+	return s
+}
+
+// SetRunID задаёт идентификатор запуска для записей лога и статистики; вызывается до Start
+func (s *Sender) SetRunID(id string) {
+	s.runID = id
+	s.stats.setRunID(id)
+}
+
+// conf возвращает текущую конфигурацию. Функции берут её один раз и дальше читают
+// полученный снимок, чтобы параллельная замена не смешала поля двух версий
+func (s *Sender) conf() *config.Config {
+	return s.cfg.Load()
+}
+
+// SetConfig заменяет конфигурацию работающего отправителя: поля, которые читаются при каждом
+// запросе, применяются со следующего запроса, изменившийся интервал — в том числе к текущему
+// ожиданию. Состояние, собранное в NewSender и Start (токены и их лимиты, бюджет повторов,
+// ограничение полосы, пояс меток времени, цели, режим отправки), остаётся прежним до
+// перезапуска. Переданную конфигурацию после вызова менять нельзя
+func (s *Sender) SetConfig(cfg *config.Config) {
+	s.cfg.Store(cfg)
+	if time.Duration(s.interval.Load()) != cfg.Interval {
+		s.SetInterval(cfg.Interval)
+	}
+}
+
+// SetInterval меняет интервал работающего отправителя, в том числе для текущего ожидания
+func (s *Sender) SetInterval(d time.Duration) {
+	s.interval.Store(int64(d))
+	select {
+	case s.intervalChanged <- struct{}{}:
+	default:
+	}
+	s.log("info", CategoryRun, i18n.T("sender.intervalChanged", d))
+}
+
+// Done закрывается, когда Start полностью завершился (включая очистку)
+func (s *Sender) Done() <-chan struct{} {
+	return s.done
+}
+
+// Причины остановки запуска
+const (
+	StopManual      = "manual"
+	StopMaxRequests = "maxRequests"
+	StopUnobserved  = "unobserved"
+	StopRampDone    = "rampComplete"
+	StopRestart     = "restart"
+	// StopSignal процесс получил сигнал завершения (SIGINT, SIGTERM)
+	StopSignal = "signal"
+	// StopPanic отправитель аварийно завершился из-за паники
+	StopPanic = "panic"
+	// StopAbortOnError Telegram вернул код ошибки из AbortOnErrorCodes
+	StopAbortOnError = "abortOnError"
+)
+
+// StopCause передаёт причину остановки через context.CancelCauseFunc
+type StopCause string
+
+func (c StopCause) Error() string {
+	return string(c)
+}
+
+// Start запускает процесс отправки сообщений и возвращает причину остановки
+func (s *Sender) Start(ctx context.Context) string {
+	cfg := s.conf()
+	defer close(s.done)
+	s.started = time.Now()
+	// Свой контекст с причиной: abortOnError останавливает запуск в любом режиме отправки
+	ctx, s.abort = context.WithCancelCause(ctx)
+	defer s.abort(nil)
+	if cfg.WarmupRequests > 0 || cfg.WarmupDuration > 0 {
+		s.log("info", CategoryRun, i18n.T("sender.warmupStart", cfg.WarmupRequests, cfg.WarmupDuration))
+	}
+	// Canary мог уже разрешить цели
+	if s.chats == nil {
+		s.chats = newChatPicker(cfg.ChatSelection, s.resolveTargets(cfg))
+	}
+	if cfg.SummaryInterval > 0 {
+		summaryCtx, stopSummary := context.WithCancel(ctx)
+		defer stopSummary()
+		s.goSafe(func() { s.summaryLoop(summaryCtx, cfg.SummaryInterval) })
+	}
+	if cfg.FailureInjectionRate > 0 {
+		s.log("warn", CategoryRun, i18n.T("sender.injectionMode", cfg.FailureInjectionRate*100))
+	}
+	if cfg.DiscardResponseBody {
+		s.log("info", CategoryRun, i18n.T("sender.discardBodyMode"))
+	}
+
+	var reason string
+	switch {
+	case cfg.StartDelay > 0 && !s.waitStartDelay(ctx, cfg.StartDelay):
+		// Остановлен во время задержки: причина — из контекста, очищать нечего
+	case cfg.RampTest != nil:
+		reason = s.runRamp(ctx)
+	case cfg.TargetLatency > 0:
+		reason = s.runLatency(ctx)
+	default:
+		reason = s.run(ctx)
+	}
+
+	if s.conf().CleanupOnStop {
+		s.cleanup()
+	}
+
+	if reason == "" {
+		reason = StopManual
+		if cause, ok := context.Cause(ctx).(StopCause); ok {
+			reason = string(cause)
+		}
 	}
+	return reason
 }
 
-// Start запускает процесс отправки сообщений
-func (s *Sender) Start(ctx context.Context) {
-	s.log("info", "========== ЗАПУСК ОТПРАВКИ ==========")
-	s.log("info", fmt.Sprintf("Конфигурация: Таймаут=%v, Интервал=%v", s.config.Timeout, s.config.Interval))
-	s.log("info", fmt.Sprintf("Chat ID: %s", s.config.ChatID))
-	s.log("info", fmt.Sprintf("Прокси: %s", func() string {
-		if s.config.ProxyURL == "" {
-			return "не используется"
+// run выполняет цикл отправки до отмены контекста или собственного условия остановки.
+// Возвращает причину самостоятельной остановки или пустую строку при отмене контекста
+func (s *Sender) run(ctx context.Context) string {
+	cfg := s.conf()
+	s.log("info", CategoryRun, i18n.T("sender.started"))
+	s.log("info", CategoryRun, i18n.T("sender.config", cfg.Timeout, cfg.Interval))
+	if cfg.Interval > 0 {
+		s.log("info", CategoryRun, i18n.T("sender.intervalMode", cfg.Interval, float64(time.Second)/float64(cfg.Interval)))
+	} else {
+		s.log("warn", CategoryRun, i18n.T("sender.unboundedMode"))
+	}
+	s.log("info", CategoryRun, i18n.T("sender.chatID", strings.Join(s.chats.targets, ", ")))
+	if cfg.MaxRequests > 0 {
+		s.log("info", CategoryRun, i18n.T("sender.maxRequests", cfg.MaxRequests))
+	}
+	if cfg.UploadFile != "" {
+		s.log("info", CategoryRun, i18n.T("sender.uploadMode", cfg.UploadFile))
+	}
+	if len(cfg.Entities) > 0 {
+		s.log("info", CategoryRun, i18n.T("sender.entitiesMode"))
+	}
+	if cfg.EditAfter > 0 {
+		s.log("info", CategoryRun, i18n.T("sender.editMode", cfg.EditAfter))
+	}
+	if cfg.QuietHoursStart != "" {
+		s.log("info", CategoryRun, i18n.T("sender.quietHours", cfg.QuietHoursStart, cfg.QuietHoursEnd))
+	}
+	if cfg.KeepAliveProbe > 0 {
+		if cfg.DisableKeepAlive {
+			s.log("warn", CategoryProbe, i18n.T("sender.probeDisabled"))
+		} else {
+			s.log("info", CategoryProbe, i18n.T("sender.probeEnabled", cfg.KeepAliveProbe))
 		}
-		return s.config.ProxyURL
+	}
+	s.log("info", CategoryRun, i18n.T("sender.proxy", func() string {
+		if cfg.ProxyURL == "" {
+			return i18n.T("sender.proxyNone")
+		}
+		return cfg.ProxyURL
 	}()))
 
 	requestNum := 0
 	for {
 		requestNum++
 		requestStart := time.Now()
+		// Конфигурация перечитывается на каждом запросе (SetConfig); список чатов остаётся
+		// прежним, так как хранит переключения после миграции
+		cfg = s.conf()
+		interval := time.Duration(s.interval.Load())
 
-		s.log("info", fmt.Sprintf("---------- Запрос #%d ----------", requestNum))
-		s.log("info", fmt.Sprintf("Время начала: %s", requestStart.Format("15:04:05.000")))
-
-		workerCtx, workerCancel := context.WithTimeout(ctx, s.config.Timeout)
-		s.log("info", fmt.Sprintf("Контекст создан с таймаутом %v", s.config.Timeout))
+		s.logReq(requestNum, "info", CategoryRequest, i18n.T("sender.requestHeader", requestNum), nil)
+		s.logReq(requestNum, "info", CategoryRequest, i18n.T("sender.requestStart", requestStart.Format("15:04:05.000")), nil)
+		targetIdx, chatID := s.pickChat(requestNum)
 
-		text := s.generateMessage()
-		s.log("info", fmt.Sprintf("Сообщение сгенерировано (%d байт)", len(text)))
+		workerCtx, workerCancel := s.withRequestTimeout(ctx, requestNum, cfg)
+		workerCtx = s.requestContext(workerCtx, requestNum)
+		s.logReq(requestNum, "info", CategoryRequest, i18n.T("sender.contextCreated", requestTimeoutOf(workerCtx, cfg)), nil)
 
-		err := s.client.SendMessage(workerCtx, s.config.ChatID, s.config.BotToken, s.config.MessageThreadID, text)
+		result, chatID, err := s.sendFollowingMigration(workerCtx, requestNum, targetIdx, chatID, requestStart)
 		workerCancel()
 
-		requestDuration := time.Since(requestStart)
-		if err != nil {
-			s.log("error", fmt.Sprintf("РЕЗУЛЬТАТ #%d: ОШИБКА за %v", requestNum, requestDuration))
-			s.log("error", fmt.Sprintf("Детали ошибки: %v", err))
+		s.recordResult(ctx, requestNum, chatID, requestStart, result, err)
+		if cfg.EditAfter > 0 && err == nil && result.MessageID != 0 {
+			s.edit(ctx, requestNum, chatID, result.MessageID)
+		}
 
-			// Проверяем тип ошибки
-			if ctx.Err() != nil {
-				s.log("error", fmt.Sprintf("Контекст родителя: %v", ctx.Err()))
-			}
-		} else {
-			s.log("info", fmt.Sprintf("РЕЗУЛЬТАТ #%d: УСПЕХ за %v", requestNum, requestDuration))
+		if cfg.MaxRequests > 0 && requestNum >= cfg.MaxRequests {
+			s.log("info", CategoryRun, i18n.T("sender.maxRequestsReached", cfg.MaxRequests))
+			return StopMaxRequests
 		}
 
 		// Вычисляем, сколько времени нужно подождать до следующего запроса
 		elapsed := time.Since(requestStart)
-		if elapsed < s.config.Interval {
-			sleepDuration := s.config.Interval - elapsed
-			s.log("info", fmt.Sprintf("Ожидание %v до следующего запроса...", sleepDuration))
-			select {
-			case <-ctx.Done():
-				s.log("info", "Получен сигнал остановки")
-				return
-			case <-time.After(sleepDuration):
+		if elapsed < interval && interval-elapsed >= cfg.MinSleep {
+			sleepDuration := interval - elapsed
+			s.logReq(requestNum, "info", CategoryWait, i18n.T("sender.waiting", sleepDuration), nil)
+			if !s.wait(ctx, requestStart) {
+				s.log("info", CategoryRun, i18n.T("sender.stopSignal"))
+				return ""
 			}
+		} else if !s.catchUp(ctx, requestNum, requestStart, elapsed, interval) {
+			s.log("info", CategoryRun, i18n.T("sender.stopSignal"))
+			return ""
+		}
+	}
+}
+
+// recordResult учитывает результат запроса в статистике и списке для очистки и логирует его.
+// Возвращает длительность запроса
+func (s *Sender) recordResult(ctx context.Context, requestNum int, chatID string, requestStart time.Time, result *telegram.SendResult, err error) time.Duration {
+	cfg := s.conf()
+	err = s.tolerateOkFalse(requestNum, requestStart, err)
+	if err == nil && cfg.CleanupOnStop {
+		s.rememberSent(requestNum, chatID, result.MessageID)
+	}
+
+	requestDuration := time.Since(requestStart)
+	stats := s.bucket(requestNum, requestStart)
+	stats.Record(chatID, err == nil, result.Timings, requestDuration)
+	event := RequestEvent{
+		RequestNum: requestNum,
+		ChatID:     chatID,
+		Success:    err == nil,
+		Duration:   requestDuration,
+		Timings:    result.Timings,
+		Warmup:     stats != s.stats,
+	}
+	resultFields := map[string]interface{}{
+		"chatID":     chatID,
+		"success":    err == nil,
+		"durationMs": durationMs(requestDuration),
+	}
+	if len(s.tokens) > 1 {
+		bot := TokenLabel(s.tokens[s.tokenIndex(requestNum)])
+		stats.recordToken(bot, err == nil)
+		resultFields["bot"] = bot
+		event.Bot = bot
+	}
+	if cfg.UploadFile != "" {
+		resultFields["bytesSent"] = result.Timings.BytesSent
+		resultFields["uploadMs"] = durationMs(result.Timings.Upload)
+	}
+	if err != nil {
+		class := errorClass(err)
+		stats.recordErrorClass(class)
+		resultFields["error"] = err.Error()
+		resultFields["errorClass"] = class
+		event.Error, event.ErrorClass = err.Error(), class
+		if kind := timeoutKind(err); kind != "" {
+			stats.recordTimeout(kind)
+			resultFields["timeout"] = kind
+		}
+		s.logReq(requestNum, "error", CategoryResult, i18n.T("sender.resultError", requestNum, requestDuration), resultFields)
+		s.logReq(requestNum, "error", CategoryRequest, i18n.T("sender.errorDetails", err), nil)
+
+		// Проверяем тип ошибки
+		if ctx.Err() != nil {
+			s.logReq(requestNum, "error", CategoryRequest, i18n.T("sender.parentContext", ctx.Err()), nil)
+		}
+	} else {
+		s.recordClockSkew(requestNum, stats, result, resultFields)
+		s.logReq(requestNum, "info", CategoryResult, i18n.T("sender.resultSuccess", requestNum, requestDuration), resultFields)
+	}
+	s.publishResult(event)
+	s.abortOnError(requestNum, err)
+	return requestDuration
+}
+
+// rememberSent запоминает отправленное сообщение для удаления при остановке (CleanupOnStop)
+func (s *Sender) rememberSent(requestNum int, chatID string, messageID int64) {
+	if messageID == 0 {
+		return
+	}
+	s.sentMu.Lock()
+	s.sent = append(s.sent, sentMessage{chatID: chatID, messageID: messageID, tokenIdx: s.tokenIndex(requestNum)})
+	s.sentMu.Unlock()
+}
+
+// send отправляет одно сообщение (или файл) в чат согласно конфигурации
+func (s *Sender) send(ctx context.Context, requestNum int, chatID string, now time.Time) (*telegram.SendResult, error) {
+	cfg := s.conf()
+	token := s.requestToken(requestNum)
+	var preSendOut string
+	if cfg.PreSendCommand != "" {
+		out, err := s.preSend(ctx, requestNum, cfg)
+		if err != nil {
+			return &telegram.SendResult{}, err
+		}
+		if cfg.PreSendField == config.PreSendFieldText {
+			preSendOut = out
 		} else {
-			s.log("warn", fmt.Sprintf("Запрос занял больше интервала (%v > %v), следующий запрос сразу", elapsed, s.config.Interval))
-			// Проверяем контекст даже если не ждём
-			select {
-			case <-ctx.Done():
-				s.log("info", "Получен сигнал остановки")
-				return
-			default:
+			token = out
+			s.preSendToken.Store(out)
+		}
+	}
+	if cfg.UploadFile != "" {
+		return s.client.SendDocument(ctx, chatID, token, s.target(cfg, chatID).MessageThreadID, cfg.UploadFile, cfg.UploadCaption)
+	}
+
+	text := cfg.MessageText
+	if len(cfg.Messages) > 0 {
+		idx := pickMessage(cfg.Messages)
+		text = cfg.Messages[idx].Text
+		s.logReq(requestNum, "info", CategoryRequest, i18n.T("sender.messagePicked", idx, cfg.Messages[idx].Weight),
+			map[string]interface{}{"messageIndex": idx})
+	} else if text == "" {
+		text = s.generateMessage()
+		s.logReq(requestNum, "info", CategoryRequest, i18n.T("sender.messageGenerated", len(text)),
+			map[string]interface{}{"bytes": len(text)})
+	}
+	if preSendOut != "" {
+		text = strings.ReplaceAll(text, config.PreSendPlaceholder, preSendOut)
+	}
+	if cfg.DetectDuplicates && s.stats.RecordMessage(text) {
+		s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.duplicateMessage"),
+			map[string]interface{}{"duplicate": true})
+	}
+	opts := messageOptions(cfg, now)
+	if cfg.QuietHoursStart != "" {
+		mode := i18n.T("sender.notifySound")
+		if opts.DisableNotification {
+			mode = i18n.T("sender.notifySilent")
+		}
+		s.logReq(requestNum, "info", CategoryRequest, i18n.T("sender.notificationMode", mode),
+			map[string]interface{}{"disableNotification": opts.DisableNotification})
+	}
+	sentAt := time.Now()
+	parts := s.fitMessage(requestNum, text, opts, visibleLength(s.withTimestamp("", sentAt, opts), opts.Markdown()))
+	var result *telegram.SendResult
+	var err error
+	// Части длинного сообщения (LongMessagePolicy=split) уходят подряд в рамках одного запроса;
+	// на первой ошибке остальные не отправляются
+	for i, part := range parts {
+		if i > 0 && cfg.CleanupOnStop {
+			s.rememberSent(requestNum, chatID, result.MessageID)
+		}
+		if result, err = s.sendText(ctx, requestNum, chatID, token, part, sentAt, opts); err != nil {
+			break
+		}
+	}
+	return result, err
+}
+
+// sendText отправляет текст с отметкой времени, при необходимости повторяя без отклонённых
+// полей цели или простым текстом
+func (s *Sender) sendText(ctx context.Context, requestNum int, chatID, token, text string, sentAt time.Time, opts telegram.MessageOptions) (*telegram.SendResult, error) {
+	cfg := s.conf()
+	target := s.target(cfg, chatID)
+	opts.ReplyToMessageID = target.ReplyToMessageID
+	result, err := s.client.SendMessage(ctx, chatID, token, target.MessageThreadID, s.withTimestamp(text, sentAt, opts), opts)
+	// Тред или сообщение для ответа не найдены: отправляем без отклонённого поля
+	for s.dropTargetField(requestNum, target, err) {
+		target = s.target(cfg, chatID)
+		opts.ReplyToMessageID = target.ReplyToMessageID
+		result, err = s.client.SendMessage(ctx, chatID, token, target.MessageThreadID, s.withTimestamp(text, sentAt, opts), opts)
+	}
+	// Разметка не разобралась: повторяем один раз простым текстом, чтобы не потерять сообщение
+	var apiErr *telegram.APIError
+	if cfg.FallbackToPlainOnParseError && errors.As(err, &apiErr) && apiErr.IsParseError() {
+		s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.plainTextFallback", apiErr.Description),
+			map[string]interface{}{"plainText": true})
+		opts.PlainText = true
+		result, err = s.client.SendMessage(ctx, chatID, token, target.MessageThreadID, s.withTimestamp(text, sentAt, opts), opts)
+	}
+	if opts.BusinessConnectionID != "" && errors.As(err, &apiErr) && apiErr.IsBusinessConnectionError() {
+		s.logReq(requestNum, "error", CategoryRequest, i18n.T("sender.businessConnectionError", opts.BusinessConnectionID, apiErr.Description),
+			map[string]interface{}{"businessConnectionID": opts.BusinessConnectionID})
+	}
+	return result, err
+}
+
+// edit через EditAfter после отправки заменяет текст сообщения на EditText (или новый
+// сгенерированный) и учитывает длительность editMessageText в статистике
+func (s *Sender) edit(ctx context.Context, requestNum int, chatID string, messageID int64) {
+	cfg := s.conf()
+	s.logReq(requestNum, "info", CategoryWait, i18n.T("sender.editWaiting", messageID, cfg.EditAfter), nil)
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(cfg.EditAfter):
+	}
+
+	text := cfg.EditText
+	if text == "" {
+		text = s.generateMessage()
+	}
+	editCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+	start := time.Now()
+	_, err := s.client.EditMessageText(s.requestContext(editCtx, requestNum), chatID, s.botToken(cfg, s.tokenIndex(requestNum)), messageID, text, messageOptions(cfg, start))
+	d := time.Since(start)
+	s.bucket(requestNum, start).RecordEdit(err == nil, d)
+
+	fields := map[string]interface{}{"chatID": chatID, "messageID": messageID, "success": err == nil, "editMs": durationMs(d)}
+	if err != nil {
+		fields["error"] = err.Error()
+		s.logReq(requestNum, "error", CategoryResult, i18n.T("sender.editError", messageID, d, err), fields)
+		return
+	}
+	s.logReq(requestNum, "info", CategoryResult, i18n.T("sender.editSuccess", messageID, d), fields)
+}
+
+// wait ждёт, пока с начала запроса пройдёт интервал, при необходимости прогревая соединение
+// пробами. Изменение интервала через SetInterval сразу пересчитывает оставшееся время.
+// Возвращает false, если контекст отменён
+func (s *Sender) wait(ctx context.Context, requestStart time.Time) bool {
+	cfg := s.conf()
+	remaining := func() time.Duration {
+		return time.Duration(s.interval.Load()) - time.Since(requestStart)
+	}
+	d := remaining()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	var probeC <-chan time.Time
+	if probe := cfg.KeepAliveProbe; probe > 0 && !cfg.DisableKeepAlive && d > probe {
+		ticker := time.NewTicker(probe)
+		defer ticker.Stop()
+		probeC = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+			return true
+		case <-s.intervalChanged:
+			d = remaining()
+			if d <= 0 {
+				return true
 			}
+			timer.Reset(d)
+		case <-probeC:
+			s.probe(ctx)
+		}
+	}
+}
+
+// probe отправляет лёгкий запрос getMe, чтобы соединение (и туннель прокси) не простаивало
+func (s *Sender) probe(ctx context.Context) {
+	cfg := s.conf()
+	probeCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := s.client.GetMe(probeCtx, s.botToken(cfg, 0))
+	duration := time.Since(start)
+	fields := map[string]interface{}{"success": err == nil, "durationMs": durationMs(duration)}
+	if err != nil {
+		s.logReq(0, "warn", CategoryProbe, i18n.T("sender.probeError", duration, err), fields)
+		return
+	}
+	s.logReq(0, "info", CategoryProbe, i18n.T("sender.probeSuccess", duration), fields)
+}
+
+// messageOptions собирает необязательные параметры sendMessage из конфигурации
+// для сообщения, отправляемого в момент now
+func messageOptions(cfg *config.Config, now time.Time) telegram.MessageOptions {
+	return telegram.MessageOptions{
+		Entities:                 cfg.Entities,
+		LinkPreviewOptions:       cfg.LinkPreviewOptions,
+		DisableNotification:      cfg.DisableNotification || cfg.InQuietHours(now),
+		ProtectContent:           cfg.ProtectContent,
+		ReplyToMessageID:         cfg.ReplyToMessageID,
+		AllowSendingWithoutReply: cfg.AllowSendingWithoutReply,
+		BusinessConnectionID:     cfg.BusinessConnectionID,
+		MessageEffectID:          cfg.MessageEffectID,
+		AllowPaidBroadcast:       cfg.AllowPaidBroadcast,
+		ExtraParams:              cfg.ExtraParams,
+	}
+}
+
+// cleanup удаляет отправленные за запуск сообщения в пределах CleanupTimeout
+func (s *Sender) cleanup() {
+	cfg := s.conf()
+	if len(s.sent) == 0 {
+		s.log("info", CategoryRun, i18n.T("sender.cleanupNothing"))
+		return
+	}
+
+	timeout := cfg.CleanupTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	s.log("info", CategoryRun, i18n.T("sender.cleanupStart", len(s.sent), timeout))
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	deleted, failed := 0, 0
+	for _, msg := range s.sent {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := s.client.DeleteMessage(ctx, msg.chatID, s.botToken(cfg, msg.tokenIdx), msg.messageID); err != nil {
+			failed++
+			s.log("warn", CategoryRun, i18n.T("sender.cleanupFailed", msg.messageID, err))
+			continue
+		}
+		deleted++
+	}
+
+	skipped := len(s.sent) - deleted - failed
+	s.sent = nil
+
+	level := "info"
+	if failed > 0 || skipped > 0 {
+		level = "warn"
+	}
+	s.logReq(0, level, CategoryRun, i18n.T("sender.cleanupDone", deleted, failed, skipped),
+		map[string]interface{}{"deleted": deleted, "failed": failed, "skipped": skipped})
+}
+
+// pickMessage выбирает индекс варианта случайно пропорционально весам
+func pickMessage(messages []config.Message) int {
+	var total float64
+	for _, m := range messages {
+		total += m.Weight
+	}
+	r := rand.Float64() * total
+	for i, m := range messages {
+		if r < m.Weight {
+			return i
+		}
+		r -= m.Weight
+	}
+	// Погрешность округления: последний вариант с ненулевым весом
+	for i := len(messages) - 1; i > 0; i-- {
+		if messages[i].Weight > 0 {
+			return i
 		}
 	}
+	return 0
 }
 
 // generateMessage генерирует тестовое сообщение
@@ -111,16 +686,129 @@ func (s *Sender) generateMessage() string {
 	)
 }
 
+// durationMs переводит длительность в миллисекунды для полей лога
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
 // log отправляет запись в канал логов
-func (s *Sender) log(level, message string) {
-	select {
-	case s.logChan <- LogEntry{
-		Time:    time.Now(),
-		Level:   level,
-		Message: message,
-	}:
+func (s *Sender) log(level, category, message string) {
+	s.logReq(0, level, category, message, nil)
+}
+
+// logReq отправляет в канал логов запись, привязанную к номеру запроса
+func (s *Sender) logReq(requestNum int, level, category, message string, fields map[string]interface{}) {
+	if level == "info" && category != CategoryResult && !s.verbose(requestNum) {
+		return
+	}
+	Emit(s.logChan, LogEntry{
+		RunID:      s.runID,
+		Time:       time.Now(),
+		Level:      level,
+		Message:    message,
+		RequestNum: requestNum,
+		Category:   category,
+		Fields:     fields,
+	})
+}
+
+// verbose сообщает, логируется ли запрос подробно: при VerboseFirstN — только первые N запросов,
+// остальные дают строку итога, предупреждения и ошибки. Записи вне запросов (номер 0) не скрываются
+func (s *Sender) verbose(requestNum int) bool {
+	n := s.conf().VerboseFirstN
+	return n <= 0 || requestNum <= n
+}
+
+// requestContext добавляет в контекст номер запроса и, если он вне подробных, режим без трейсинга
+func (s *Sender) requestContext(ctx context.Context, requestNum int) context.Context {
+	ctx = telegram.WithRequestNum(ctx, requestNum)
+	if s.verbose(requestNum) {
+		return ctx
+	}
+	if n := s.conf().VerboseFirstN; requestNum == n+1 {
+		s.log("info", CategoryRun, i18n.T("sender.verboseEnded", n))
+	}
+	return telegram.WithQuiet(ctx)
+}
+
+// droppedLogs считает записи, пропущенные из-за переполнения канала логов
+var droppedLogs atomic.Int64
+
+// Политики переполнения канала логов
+const (
+	OverflowDropNewest = "drop-newest"
+	OverflowDropOldest = "drop-oldest"
+	OverflowBlock      = "block"
+)
+
+var overflowPolicy atomic.Value
+
+func init() {
+	overflowPolicy.Store(OverflowDropNewest)
+}
+
+// SetOverflowPolicy задаёт политику переполнения для всех каналов логов (пустая — drop-newest)
+func SetOverflowPolicy(policy string) {
+	if policy == "" {
+		policy = OverflowDropNewest
+	}
+	overflowPolicy.Store(policy)
+}
+
+// OverflowPolicy возвращает текущую политику переполнения
+func OverflowPolicy() string {
+	return overflowPolicy.Load().(string)
+}
+
+// Emit отправляет запись в канал логов с учётом политики переполнения.
+// Вытесненные и пропущенные записи учитываются в DroppedLogs
+func Emit(logChan chan LogEntry, entry LogEntry) {
+	CountDropped(Deliver(logChan, entry))
+}
+
+// CountDropped учитывает в DroppedLogs записи, потерянные при доставке вне Emit
+// (например, в очереди подписчиков)
+func CountDropped(n int) {
+	if n > 0 {
+		droppedLogs.Add(int64(n))
+	}
+}
+
+// Deliver кладёт запись в канал согласно политике переполнения и возвращает число
+// потерянных записей (новая при drop-newest, вытесненная старая при drop-oldest).
+// При политике block ждёт места в канале без ограничения: если канал никто не читает,
+// вызывающий (и отправитель, пишущий лог через Emit) останавливается до его освобождения
+func Deliver(ch chan LogEntry, entry LogEntry) int {
+	switch OverflowPolicy() {
+	case OverflowBlock:
+		ch <- entry
+		return 0
+	case OverflowDropOldest:
+		dropped := 0
+		for {
+			select {
+			case ch <- entry:
+				return dropped
+			default:
+			}
+			// Канал полон: вытесняем самую старую запись и пробуем снова
+			select {
+			case <-ch:
+				dropped++
+			default:
+			}
+		}
 	default:
-		// Если канал переполнен, пропускаем запись
+		select {
+		case ch <- entry:
+			return 0
+		default:
+			return 1
+		}
 	}
 }
 
+// DroppedLogs возвращает число пропущенных записей лога с момента запуска процесса
+func DroppedLogs() int64 {
+	return droppedLogs.Load()
+}
@@ -0,0 +1,93 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// newBotAPI httptest-сервер, отвечающий на sendMessage успехом; hits — число запросов
+func newBotAPI(t *testing.T, hits *atomic.Int64) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d,"date":%d,"chat":{"id":1}}}`, n, time.Now().Unix())
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// drainLogs создаёт канал лога и вычитывает его до конца теста
+func drainLogs(t *testing.T) chan LogEntry {
+	t.Helper()
+	logChan := make(chan LogEntry, 1024)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range logChan {
+		}
+	}()
+	t.Cleanup(func() {
+		close(logChan)
+		<-done
+	})
+	return logChan
+}
+
+// testSenderConfig конфигурация для запросов к httptest-серверу: короткий интервал, без ChatSpacing
+func testSenderConfig() *config.Config {
+	cfg := config.Default()
+	cfg.BotToken = "123:test"
+	cfg.ChatID = "1"
+	cfg.Interval = 5 * time.Millisecond
+	cfg.Timeout = 5 * time.Second
+	cfg.ChatSpacing = -1
+	return cfg
+}
+
+func TestSetConfigWhileRunning(t *testing.T) {
+	var hits atomic.Int64
+	srv := newBotAPI(t, &hits)
+
+	cfg := testSenderConfig()
+	client, err := telegram.NewClient(cfg.Timeout, "", false, "", func(string, string, telegram.LogMeta) {},
+		telegram.WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snd := NewSender(cfg, client, NewStats(), drainLogs(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go snd.Start(ctx)
+
+	// Конфигурация заменяется целиком, пока цикл отправки читает её снимки
+	for i := 0; i < 200; i++ {
+		next := *cfg
+		next.Interval = time.Duration(1+i%5) * time.Millisecond
+		next.MessageText = fmt.Sprintf("сообщение %d", i)
+		snd.SetConfig(&next)
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case <-snd.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("отправитель не остановился после отмены контекста")
+	}
+	if hits.Load() == 0 {
+		t.Fatal("за время теста не отправлено ни одного сообщения")
+	}
+	if got := snd.conf().MessageText; got != "сообщение 199" {
+		t.Errorf("текущая конфигурация %q, ожидалась последняя переданная", got)
+	}
+}
@@ -0,0 +1,46 @@
+package sender
+
+import (
+	"time"
+
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// RequestEvent итог одного запроса для приёмников результатов
+type RequestEvent struct {
+	RunID      string
+	RequestNum int
+	ChatID     string
+	// Bot ID бота при ротации токенов (BotTokens), иначе пусто
+	Bot      string
+	Success  bool
+	Duration time.Duration
+	// Timings этапы последней попытки
+	Timings telegram.Timings
+	// ErrorClass класс ошибки (errorClasses) и текст ошибки; пусто при успехе
+	ErrorClass string
+	Error      string
+	// Warmup запрос прогрева (WarmupRequests, WarmupDuration), в основную статистику не входит
+	Warmup bool
+}
+
+// ResultSink приёмник результатов запросов и записей лога (StatsD, база данных, файл).
+// Методы вызываются из горутин отправителя и broadcaster, поэтому должны быть безопасны
+// для конкурентного вызова и возвращаться быстро: медленный приёмник тормозит отправку
+type ResultSink interface {
+	RecordResult(RequestEvent)
+	RecordLog(LogEntry)
+}
+
+// AddResultSink подключает приёмник результатов запросов; вызывается до Start
+func (s *Sender) AddResultSink(sink ResultSink) {
+	s.sinks = append(s.sinks, sink)
+}
+
+// publishResult передаёт итог запроса подключённым приёмникам
+func (s *Sender) publishResult(event RequestEvent) {
+	event.RunID = s.runID
+	for _, sink := range s.sinks {
+		sink.RecordResult(event)
+	}
+}
@@ -0,0 +1,78 @@
+package sender
+
+import (
+	"context"
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// reserve занимает ближайший момент отправки в чат не раньше spacing после предыдущего
+// и возвращает его и сколько до него ждать. Момент резервируется сразу, поэтому параллельные
+// воркеры выстраиваются в очередь к чату, а к разным чатам идут независимо
+func (l *rateLimits) reserve(chatID string, spacing time.Duration) (time.Time, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	slot := l.slots[chatID]
+	if slot.Before(now) {
+		slot = now
+		// Очередь к чату пуста — отменённые брони из неё больше не нужны
+		delete(l.freed, chatID)
+	}
+	l.slots[chatID] = slot.Add(spacing)
+	return slot, slot.Sub(now)
+}
+
+// release возвращает неиспользованную бронь slot (ожидание отменено). Если бронь последняя
+// в очереди чата, очередь укорачивается вместе с ранее отменёнными бронями перед ней;
+// иначе бронь запоминается и снимется, когда до неё дойдёт укорачивание
+func (l *rateLimits) release(chatID string, slot time.Time, spacing time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	end := slot.Add(spacing)
+	if !l.slots[chatID].Equal(end) {
+		if l.freed[chatID] == nil {
+			l.freed[chatID] = make(map[int64]time.Time)
+		}
+		l.freed[chatID][end.UnixNano()] = slot
+		return
+	}
+	l.slots[chatID] = slot
+	for {
+		start, ok := l.freed[chatID][slot.UnixNano()]
+		if !ok {
+			return
+		}
+		delete(l.freed[chatID], slot.UnixNano())
+		slot = start
+		l.slots[chatID] = slot
+	}
+}
+
+// awaitChatSpacing выдерживает минимальный промежуток между отправками в чат (ChatSpacing)
+// среди всех воркеров запуска. false — контекст отменён раньше, бронь при этом возвращается
+func (s *Sender) awaitChatSpacing(ctx context.Context, requestNum int, chatID string) bool {
+	spacing := s.conf().MinChatSpacing()
+	if spacing <= 0 {
+		return true
+	}
+	limits := s.limitsFor(requestNum)
+	slot, delay := limits.reserve(chatID, spacing)
+	if delay <= 0 {
+		return true
+	}
+	s.logReq(requestNum, "info", CategoryWait, i18n.T("sender.chatSpacingWait", chatID, delay, spacing),
+		map[string]interface{}{"chatID": chatID, "delayMs": durationMs(delay), "chatSpacingMs": durationMs(spacing)})
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		limits.release(chatID, slot, spacing)
+		return false
+	case <-timer.C:
+		return true
+	}
+}
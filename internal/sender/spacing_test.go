@@ -0,0 +1,127 @@
+package sender
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"SendMsgTestForTG/internal/config"
+)
+
+// newSpacingSender отправитель без клиента: проверяется только ожидание ChatSpacing
+func newSpacingSender(t *testing.T, spacing time.Duration) *Sender {
+	t.Helper()
+	cfg := config.Default()
+	cfg.ChatSpacing = spacing
+	logChan := make(chan LogEntry, 1024)
+	t.Cleanup(func() { close(logChan) })
+	go func() {
+		for range logChan {
+		}
+	}()
+	return NewSender(cfg, nil, NewStats(), logChan)
+}
+
+func TestChatSpacingConcurrentWorkers(t *testing.T) {
+	const spacing = 40 * time.Millisecond
+	s := newSpacingSender(t, spacing)
+
+	var (
+		mu     sync.Mutex
+		passed []time.Time
+		wg     sync.WaitGroup
+	)
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := context.Background()
+			// Каждый третий воркер сдаётся раньше своей очереди
+			if i%3 == 2 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, spacing/2)
+				defer cancel()
+			}
+			if s.awaitChatSpacing(ctx, i+1, "chat") {
+				mu.Lock()
+				passed = append(passed, time.Now())
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Slice(passed, func(i, j int) bool { return passed[i].Before(passed[j]) })
+	if len(passed) < 8 {
+		t.Fatalf("прошло %d воркеров, ожидалось не меньше 8", len(passed))
+	}
+	// Таймеры срабатывают не раньше срока, небольшой допуск на округление часов
+	for i := 1; i < len(passed); i++ {
+		if gap := passed[i].Sub(passed[i-1]); gap < spacing-2*time.Millisecond {
+			t.Errorf("промежуток между отправками %d и %d: %v < %v", i-1, i, gap, spacing)
+		}
+	}
+}
+
+func TestChatSpacingCancelledReservationsReleased(t *testing.T) {
+	const spacing = 100 * time.Millisecond
+	s := newSpacingSender(t, spacing)
+
+	// Первая бронь проходит сразу, остальные отменяются до своей очереди
+	if !s.awaitChatSpacing(context.Background(), 1, "chat") {
+		t.Fatal("первая отправка должна пройти без ожидания")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if s.awaitChatSpacing(ctx, i+2, "chat") {
+				t.Errorf("воркер %d прошёл до своей очереди", i+2)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	limits := s.limitsFor(1)
+	_, delay := limits.reserve("chat", spacing)
+	if delay > spacing {
+		t.Errorf("после отмен очередь к чату не вернулась: ожидание %v > %v", delay, spacing)
+	}
+	limits.mu.Lock()
+	freed := len(limits.freed["chat"])
+	limits.mu.Unlock()
+	if freed != 0 {
+		t.Errorf("остались неснятые отменённые брони: %d", freed)
+	}
+}
+
+func TestReleaseOutOfOrder(t *testing.T) {
+	const spacing = time.Second
+	l := newRateLimits()
+	first, _ := l.reserve("chat", spacing)
+	a, _ := l.reserve("chat", spacing)
+	b, _ := l.reserve("chat", spacing)
+	c, _ := l.reserve("chat", spacing)
+
+	// Отмена из середины очереди не укорачивает её, пока не отменён хвост
+	l.release("chat", b, spacing)
+	if want := c.Add(spacing); !l.slots["chat"].Equal(want) {
+		t.Fatalf("хвост очереди %v, ожидался %v", l.slots["chat"], want)
+	}
+	l.release("chat", c, spacing)
+	if !l.slots["chat"].Equal(b) {
+		t.Fatalf("после отмены хвоста очередь должна укоротиться до %v, получено %v", b, l.slots["chat"])
+	}
+	l.release("chat", a, spacing)
+	if want := first.Add(spacing); !l.slots["chat"].Equal(want) {
+		t.Fatalf("после всех отмен очередь должна закончиться на %v, получено %v", want, l.slots["chat"])
+	}
+	if len(l.freed["chat"]) != 0 {
+		t.Errorf("остались неснятые брони: %v", l.freed["chat"])
+	}
+}
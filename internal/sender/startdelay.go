@@ -0,0 +1,26 @@
+package sender
+
+import (
+	"context"
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// waitStartDelay выдерживает StartDelay перед первым запросом, например чтобы несколько
+// экземпляров стартовали вместе или успел подняться прокси. Возвращает false, если запуск
+// остановили во время ожидания
+func (s *Sender) waitStartDelay(ctx context.Context, delay time.Duration) bool {
+	s.log("info", CategoryRun, i18n.T("sender.startDelay", delay, time.Now().Add(delay).Format("15:04:05.000")))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+	}
+	// Прогрев по времени отсчитывается от первого запроса, а не от начала ожидания
+	s.started = time.Now()
+	s.log("info", CategoryRun, i18n.T("sender.startDelayDone", time.Now().Format("15:04:05.000")))
+	return true
+}
@@ -0,0 +1,484 @@
+package sender
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// maxSamples ограничивает число хранимых замеров на этап (для перцентилей)
+const maxSamples = 10000
+
+// maxTrackedChats ограничивает число чатов с отдельными счётчиками; остальные учитываются в ChatOther
+const maxTrackedChats = 100
+
+// maxTrackedMessages ограничивает число хешей сообщений для поиска повторов; старые вытесняются
+const maxTrackedMessages = 50000
+
+// ChatOther ключ разбивки по чатам для чатов сверх maxTrackedChats
+const ChatOther = "other"
+
+// Этапы запроса в статистике
+const (
+	PhaseDNS      = "dns"
+	PhaseConnect  = "connect"
+	PhaseTLS      = "tls"
+	PhaseTTFB     = "ttfb"
+	PhaseBodyRead = "bodyRead"
+	PhaseUpload   = "upload"
+	PhaseTotal    = "total"
+	// PhaseEdit длительность editMessageText в режиме отправки с изменением
+	PhaseEdit = "edit"
+)
+
+// Stats собирает статистику запросов за запуск
+type Stats struct {
+	mu    sync.Mutex
+	runID string
+	// label метка запуска (RunLabel)
+	label   string
+	started time.Time
+	total   int
+	success int
+	errors  int
+	phases  map[string]*samples
+	chats   map[string]*ChatStats
+	// seen хеши отправленных текстов (DetectDuplicates), seenOrder — порядок для вытеснения
+	seen       map[uint64]struct{}
+	seenOrder  []uint64
+	seenNext   int
+	duplicates int
+	// bytesSent и bytesReceived тела и приблизительный объём заголовков за запуск
+	bytesSent     int64
+	bytesReceived int64
+	// retries выполненные повторы, retriesDenied — отклонённые бюджетом; budget — общий бюджет повторов
+	retries       int
+	retriesDenied int
+	budget        *retryBudget
+	// edits и editErrors изменения сообщений (EditAfter); в total/success/errors не входят
+	edits      int
+	editErrors int
+	// okFalse ответы ok=false, засчитанные успехом при TreatOkFalseAs=warn
+	okFalse int
+	// errorClasses ошибки запросов по классам (errorClass)
+	errorClasses map[string]int
+	// timeouts запросы, завершившиеся таймаутом, по видам (timeoutKind)
+	timeouts map[string]int
+	// tokens счётчики по ботам (ID бота) при ротации BotTokens
+	tokens map[string]*ChatStats
+	// warmup отдельная статистика запросов прогрева (WarmupRequests, WarmupDuration)
+	warmup *Stats
+	// skew сумма, минимум, максимум и число оценок расхождения часов с Telegram (TrackClockSkew)
+	skewSum   time.Duration
+	skewMin   time.Duration
+	skewMax   time.Duration
+	skewCount int
+	// connsNew и connsReused запросы, получившие новое и переиспользованное соединение
+	connsNew    int
+	connsReused int
+}
+
+// samples кольцевой буфер последних замеров
+type samples struct {
+	values []time.Duration
+	next   int
+	count  int
+	sum    time.Duration
+	max    time.Duration
+}
+
+// StatsSnapshot снимок статистики для API
+type StatsSnapshot struct {
+	RunID    string                `json:"runId,omitempty"`
+	RunLabel string                `json:"runLabel,omitempty"`
+	Started  time.Time             `json:"started"`
+	Total    int                   `json:"total"`
+	Success  int                   `json:"success"`
+	Errors   int                   `json:"errors"`
+	Phases   map[string]PhaseStats `json:"phases"`
+	Chats    map[string]ChatStats  `json:"chats"`
+	// Duplicates число сообщений, совпавших с ранее отправленным (только при DetectDuplicates)
+	Duplicates int `json:"duplicates"`
+	// BytesSent и BytesReceived трафик запросов: тела плюс оценка заголовков
+	BytesSent     int64 `json:"bytesSent"`
+	BytesReceived int64 `json:"bytesReceived"`
+	// Retries выполненные повторы, RetriesDenied — пропущенные из-за исчерпанного бюджета
+	Retries       int               `json:"retries"`
+	RetriesDenied int               `json:"retriesDenied"`
+	RetryBudget   *RetryBudgetState `json:"retryBudget,omitempty"`
+	// Edits и EditErrors число изменений сообщений и неудачных из них; длительности — в этапе edit
+	Edits      int `json:"edits"`
+	EditErrors int `json:"editErrors"`
+	// OkFalseTolerated ответы ok=false, засчитанные успехом (TreatOkFalseAs=warn); входят в success
+	OkFalseTolerated int `json:"okFalseTolerated"`
+	// ErrorClasses ошибки по классам: 4xx, 429, 5xx, network, injected, other
+	ErrorClasses map[string]int `json:"errorClasses"`
+	// Timeouts запросы, завершившиеся таймаутом: client (Timeout), context (RequestTimeout), header (HeaderTimeout), cycleBudget (PerCycleBudget)
+	Timeouts map[string]int `json:"timeouts"`
+	// Tokens счётчики запросов по ботам (ID бота из токена); только при ротации BotTokens
+	Tokens map[string]ChatStats `json:"tokens,omitempty"`
+	// Warmup статистика прогрева; в остальные поля снимка запросы прогрева не входят
+	Warmup *StatsSnapshot `json:"warmup,omitempty"`
+	// ClockSkew расхождение часов Telegram с локальными; только при TrackClockSkew
+	ClockSkew *ClockSkewStats `json:"clockSkew,omitempty"`
+	// Connections работа keep-alive: сколько соединений открыто и сколько запросов пришлось на одно
+	Connections ConnectionStats `json:"connections"`
+}
+
+// ConnectionStats соединения запуска по последней попытке каждого запроса. RequestsPerConn —
+// запросы, получившие соединение, на одно новое; 1 значит, что keep-alive не работает
+type ConnectionStats struct {
+	Opened          int     `json:"opened"`
+	Reused          int     `json:"reused"`
+	RequestsPerConn float64 `json:"requestsPerConn"`
+}
+
+// ClockSkewStats расхождение часов Telegram с локальными в миллисекундах; положительное — часы Telegram спешат
+type ClockSkewStats struct {
+	Count int     `json:"count"`
+	AvgMs float64 `json:"avgMs"`
+	MinMs float64 `json:"minMs"`
+	MaxMs float64 `json:"maxMs"`
+}
+
+// ChatStats счётчики запросов к одному чату
+type ChatStats struct {
+	Total       int     `json:"total"`
+	Success     int     `json:"success"`
+	Errors      int     `json:"errors"`
+	FailureRate float64 `json:"failureRate"`
+}
+
+// PhaseStats агрегированные значения одного этапа в миллисекундах
+type PhaseStats struct {
+	Count int     `json:"count"`
+	AvgMs float64 `json:"avgMs"`
+	P50Ms float64 `json:"p50Ms"`
+	P90Ms float64 `json:"p90Ms"`
+	P95Ms float64 `json:"p95Ms"`
+	P99Ms float64 `json:"p99Ms"`
+	MaxMs float64 `json:"maxMs"`
+}
+
+// NewStats создаёт пустую статистику
+func NewStats() *Stats {
+	return &Stats{
+		started:      time.Now(),
+		phases:       make(map[string]*samples),
+		chats:        make(map[string]*ChatStats),
+		errorClasses: make(map[string]int),
+		timeouts:     make(map[string]int),
+	}
+}
+
+// Record учитывает результат запроса к чату и замеры его этапов
+func (st *Stats) Record(chatID string, success bool, t telegram.Timings, total time.Duration) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.total++
+	if success {
+		st.success++
+	} else {
+		st.errors++
+	}
+	st.recordChat(chatID, success)
+	st.bytesSent += t.BytesSent + t.HeaderBytesSent
+	st.bytesReceived += t.BytesReceived + t.HeaderBytesReceived
+	switch {
+	case t.NewConn:
+		st.connsNew++
+	case t.ConnReused:
+		st.connsReused++
+	}
+
+	st.add(PhaseDNS, t.DNS)
+	st.add(PhaseConnect, t.Connect)
+	st.add(PhaseTLS, t.TLS)
+	st.add(PhaseTTFB, t.TTFB)
+	st.add(PhaseBodyRead, t.BodyRead)
+	st.add(PhaseUpload, t.Upload)
+	st.add(PhaseTotal, total)
+}
+
+// recordChat обновляет счётчики чата; новые чаты сверх лимита попадают в ChatOther
+func (st *Stats) recordChat(chatID string, success bool) {
+	chat, ok := st.chats[chatID]
+	if !ok {
+		if len(st.chats) >= maxTrackedChats {
+			chatID = ChatOther
+		}
+		if chat, ok = st.chats[chatID]; !ok {
+			chat = &ChatStats{}
+			st.chats[chatID] = chat
+		}
+	}
+	chat.Total++
+	if success {
+		chat.Success++
+	} else {
+		chat.Errors++
+	}
+}
+
+// RecordMessage запоминает хеш текста сообщения и сообщает, отправлялся ли такой текст раньше.
+// Хранятся последние maxTrackedMessages хешей
+func (st *Stats) RecordMessage(text string) bool {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	sum := h.Sum64()
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if _, ok := st.seen[sum]; ok {
+		st.duplicates++
+		return true
+	}
+	if st.seen == nil {
+		st.seen = make(map[uint64]struct{})
+	}
+	if len(st.seenOrder) < maxTrackedMessages {
+		st.seenOrder = append(st.seenOrder, sum)
+	} else {
+		delete(st.seen, st.seenOrder[st.seenNext])
+		st.seenOrder[st.seenNext] = sum
+		st.seenNext = (st.seenNext + 1) % maxTrackedMessages
+	}
+	st.seen[sum] = struct{}{}
+	return false
+}
+
+// RecordEdit учитывает изменение сообщения и его длительность отдельно от отправок
+func (st *Stats) RecordEdit(success bool, d time.Duration) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.edits++
+	if !success {
+		st.editErrors++
+	}
+	st.add(PhaseEdit, d)
+}
+
+// recordOkFalse учитывает ответ ok=false, засчитанный успехом
+func (st *Stats) recordOkFalse() {
+	st.mu.Lock()
+	st.okFalse++
+	st.mu.Unlock()
+}
+
+// recordErrorClass учитывает класс ошибки неуспешного запроса
+func (st *Stats) recordErrorClass(class string) {
+	st.mu.Lock()
+	st.errorClasses[class]++
+	st.mu.Unlock()
+}
+
+// Warmup возвращает статистику прогрева, создавая её при первом обращении
+func (st *Stats) Warmup() *Stats {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.warmup == nil {
+		st.warmup = NewStats()
+		st.warmup.runID, st.warmup.label = st.runID, st.label
+	}
+	return st.warmup
+}
+
+// recordClockSkew учитывает оценку расхождения часов одного запроса
+func (st *Stats) recordClockSkew(skew time.Duration) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.skewCount == 0 || skew < st.skewMin {
+		st.skewMin = skew
+	}
+	if st.skewCount == 0 || skew > st.skewMax {
+		st.skewMax = skew
+	}
+	st.skewSum += skew
+	st.skewCount++
+}
+
+// recordToken учитывает результат запроса для бота с ID bot
+func (st *Stats) recordToken(bot string, success bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.tokens == nil {
+		st.tokens = make(map[string]*ChatStats)
+	}
+	counts, ok := st.tokens[bot]
+	if !ok {
+		counts = &ChatStats{}
+		st.tokens[bot] = counts
+	}
+	counts.Total++
+	if success {
+		counts.Success++
+	} else {
+		counts.Errors++
+	}
+}
+
+// recordTimeout учитывает вид таймаута неуспешного запроса
+func (st *Stats) recordTimeout(kind string) {
+	st.mu.Lock()
+	st.timeouts[kind]++
+	st.mu.Unlock()
+}
+
+// setRunID задаёт идентификатор запуска для снимка
+func (st *Stats) setRunID(id string) {
+	st.mu.Lock()
+	st.runID = id
+	st.mu.Unlock()
+}
+
+// setRunLabel задаёт метку запуска (RunLabel) для снимка
+func (st *Stats) setRunLabel(label string) {
+	st.mu.Lock()
+	st.label = label
+	st.mu.Unlock()
+}
+
+// setRetryBudget подключает бюджет повторов для отображения в снимке
+func (st *Stats) setRetryBudget(b *retryBudget) {
+	st.mu.Lock()
+	st.budget = b
+	st.mu.Unlock()
+}
+
+// recordRetry учитывает повтор: выполненный или отклонённый бюджетом
+func (st *Stats) recordRetry(allowed bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if allowed {
+		st.retries++
+	} else {
+		st.retriesDenied++
+	}
+}
+
+// add добавляет замер этапа; нулевые значения (этап не выполнялся) пропускаются
+func (st *Stats) add(phase string, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	smp, ok := st.phases[phase]
+	if !ok {
+		smp = &samples{values: make([]time.Duration, 0, 64)}
+		st.phases[phase] = smp
+	}
+	if len(smp.values) < maxSamples {
+		smp.values = append(smp.values, d)
+	} else {
+		smp.values[smp.next] = d
+		smp.next = (smp.next + 1) % maxSamples
+	}
+	smp.count++
+	smp.sum += d
+	if d > smp.max {
+		smp.max = d
+	}
+}
+
+// Snapshot возвращает копию текущей статистики
+func (st *Stats) Snapshot() StatsSnapshot {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	snap := StatsSnapshot{
+		RunID:            st.runID,
+		RunLabel:         st.label,
+		Started:          st.started,
+		Total:            st.total,
+		Success:          st.success,
+		Errors:           st.errors,
+		Phases:           make(map[string]PhaseStats, len(st.phases)),
+		Chats:            make(map[string]ChatStats, len(st.chats)),
+		Duplicates:       st.duplicates,
+		BytesSent:        st.bytesSent,
+		BytesReceived:    st.bytesReceived,
+		Retries:          st.retries,
+		RetriesDenied:    st.retriesDenied,
+		Edits:            st.edits,
+		EditErrors:       st.editErrors,
+		OkFalseTolerated: st.okFalse,
+		ErrorClasses:     make(map[string]int, len(st.errorClasses)),
+		Timeouts:         make(map[string]int, len(st.timeouts)),
+		Connections:      ConnectionStats{Opened: st.connsNew, Reused: st.connsReused},
+	}
+	if st.connsNew > 0 {
+		snap.Connections.RequestsPerConn = float64(st.connsNew+st.connsReused) / float64(st.connsNew)
+	}
+	for class, n := range st.errorClasses {
+		snap.ErrorClasses[class] = n
+	}
+	for kind, n := range st.timeouts {
+		snap.Timeouts[kind] = n
+	}
+	if st.skewCount > 0 {
+		snap.ClockSkew = &ClockSkewStats{
+			Count: st.skewCount,
+			AvgMs: durationMs(st.skewSum / time.Duration(st.skewCount)),
+			MinMs: durationMs(st.skewMin),
+			MaxMs: durationMs(st.skewMax),
+		}
+	}
+	if st.warmup != nil {
+		warmup := st.warmup.Snapshot()
+		snap.Warmup = &warmup
+	}
+	if len(st.tokens) > 0 {
+		snap.Tokens = make(map[string]ChatStats, len(st.tokens))
+		for bot, counts := range st.tokens {
+			c := *counts
+			c.FailureRate = float64(c.Errors) / float64(c.Total)
+			snap.Tokens[bot] = c
+		}
+	}
+	if st.budget != nil {
+		state := st.budget.state()
+		snap.RetryBudget = &state
+	}
+	for name, smp := range st.phases {
+		snap.Phases[name] = smp.stats()
+	}
+	for id, chat := range st.chats {
+		c := *chat
+		c.FailureRate = float64(c.Errors) / float64(c.Total)
+		snap.Chats[id] = c
+	}
+	return snap
+}
+
+// stats вычисляет среднее и максимум (по всем замерам) и перцентили (по окну последних замеров)
+func (smp *samples) stats() PhaseStats {
+	sorted := make([]time.Duration, len(smp.values))
+	copy(sorted, smp.values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	percentile := func(p float64) float64 {
+		idx := int(p*float64(len(sorted))+0.5) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		if idx >= len(sorted) {
+			idx = len(sorted) - 1
+		}
+		return durationMs(sorted[idx])
+	}
+
+	return PhaseStats{
+		Count: smp.count,
+		AvgMs: durationMs(smp.sum / time.Duration(smp.count)),
+		P50Ms: percentile(0.50),
+		P90Ms: percentile(0.90),
+		P95Ms: percentile(0.95),
+		P99Ms: percentile(0.99),
+		MaxMs: durationMs(smp.max),
+	}
+}
@@ -0,0 +1,45 @@
+package sender
+
+import (
+	"context"
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// summaryLoop каждые interval пишет в лог сводку запуска: итоги с начала запуска и темп
+// со средней длительностью запроса за прошедший период. Завершается с отменой ctx
+func (s *Sender) summaryLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prev := s.stats.Snapshot()
+	prevAt := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			snap := s.stats.Snapshot()
+			requests := snap.Total - prev.Total
+			rate := float64(requests) / now.Sub(prevAt).Seconds()
+
+			// Среднее за период: разность сумм длительностей, восстановленных из среднего за запуск
+			var avgMs float64
+			cur, old := snap.Phases[PhaseTotal], prev.Phases[PhaseTotal]
+			if n := cur.Count - old.Count; n > 0 {
+				avgMs = (cur.AvgMs*float64(cur.Count) - old.AvgMs*float64(old.Count)) / float64(n)
+			}
+
+			s.logReq(0, "info", CategorySummary, i18n.T("sender.summary", snap.Total, snap.Success, snap.Errors, rate, avgMs),
+				map[string]interface{}{
+					"total":   snap.Total,
+					"success": snap.Success,
+					"errors":  snap.Errors,
+					"rps":     rate,
+					"avgMs":   avgMs,
+				})
+			prev, prevAt = snap, now
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package sender
+
+import (
+	"errors"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// Поля адреса, которые отбрасываются при TargetFallback
+const (
+	TargetFieldThread = "messageThreadID"
+	TargetFieldReply  = "replyToMessageID"
+)
+
+// target адрес сообщения для чата chatID без полей, которые Telegram уже отклонил для этого чата
+func (s *Sender) target(cfg *config.Config, chatID string) config.TargetSpec {
+	t := cfg.Target(chatID)
+	s.targetMu.Lock()
+	defer s.targetMu.Unlock()
+	if s.targetDrops[chatID][TargetFieldThread] {
+		t.MessageThreadID = ""
+	}
+	if s.targetDrops[chatID][TargetFieldReply] {
+		t.ReplyToMessageID = ""
+	}
+	return t
+}
+
+// dropTargetField при TargetFallback отбрасывает поле адреса t, которое Telegram отклонил
+// ошибкой err: тред или сообщение для ответа не найдено. Поле не передаётся в этот чат до
+// конца запуска. true — поле отброшено и запрос стоит повторить без него
+func (s *Sender) dropTargetField(requestNum int, t config.TargetSpec, err error) bool {
+	var apiErr *telegram.APIError
+	if !s.conf().TargetFallback || !errors.As(err, &apiErr) {
+		return false
+	}
+	var field string
+	switch {
+	case t.MessageThreadID != "" && apiErr.IsThreadError():
+		field = TargetFieldThread
+	case t.ReplyToMessageID != "" && apiErr.IsReplyError():
+		field = TargetFieldReply
+	default:
+		return false
+	}
+	s.targetMu.Lock()
+	if s.targetDrops == nil {
+		s.targetDrops = make(map[string]map[string]bool)
+	}
+	if s.targetDrops[t.ChatID] == nil {
+		s.targetDrops[t.ChatID] = make(map[string]bool)
+	}
+	s.targetDrops[t.ChatID][field] = true
+	s.targetMu.Unlock()
+	s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.targetFieldDropped", field, t.ChatID, apiErr.Description),
+		map[string]interface{}{"chatID": t.ChatID, "droppedField": field})
+	return true
+}
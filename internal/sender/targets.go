@@ -0,0 +1,104 @@
+package sender
+
+import (
+	"math/rand"
+	"sync"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// chatPicker выбирает чат для каждого запроса по стратегии ChatSelection.
+// Безопасен для параллельных запросов ramp-теста и режима задержки
+type chatPicker struct {
+	mu      sync.Mutex
+	mode    string
+	targets []string
+	// order порядок обхода текущего прохода (sequentialExhaust), pos — позиция в нём
+	order []int
+	pos   int
+}
+
+// newChatPicker создаёт выбор чатов; пустая стратегия означает roundRobin
+func newChatPicker(mode string, targets []string) *chatPicker {
+	if mode == "" {
+		mode = config.ChatSelectionRoundRobin
+	}
+	return &chatPicker{mode: mode, targets: targets}
+}
+
+// next возвращает индекс и ID чата для запроса requestNum
+func (p *chatPicker) next(requestNum int) (int, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var idx int
+	switch p.mode {
+	case config.ChatSelectionRandom:
+		idx = rand.Intn(len(p.targets))
+	case config.ChatSelectionSequentialExhaust:
+		// Каждый проход отправляет во все чаты ровно по разу, порядок проходов перемешивается
+		if p.pos == len(p.order) {
+			p.order = rand.Perm(len(p.targets))
+			p.pos = 0
+		}
+		idx = p.order[p.pos]
+		p.pos++
+	default:
+		idx = (requestNum - 1) % len(p.targets)
+	}
+	return idx, p.targets[idx]
+}
+
+// replace заменяет ID чата (миграция в супергруппу) до конца запуска
+func (p *chatPicker) replace(idx int, chatID string) {
+	p.mu.Lock()
+	p.targets[idx] = chatID
+	p.mu.Unlock()
+}
+
+// pickChat выбирает чат для запроса и логирует выбор, если чатов несколько. Чат на паузе
+// после 429 пропускается в пользу следующего свободного; если свободных нет, остаётся выбранный
+func (s *Sender) pickChat(requestNum int) (int, string) {
+	idx, chatID := s.chats.next(requestNum)
+	for i := 1; i < len(s.chats.targets); i++ {
+		delay, scope := s.limitsFor(requestNum).delay(chatID)
+		if delay <= 0 || scope == RateLimitGlobal {
+			break
+		}
+		s.logReq(requestNum, "info", CategoryRequest, i18n.T("sender.rateLimitSkip", chatID, delay),
+			map[string]interface{}{"chatID": chatID, "delayMs": durationMs(delay)})
+		idx, chatID = s.chats.next(requestNum + i)
+	}
+	if len(s.chats.targets) > 1 {
+		s.logReq(requestNum, "info", CategoryRequest, i18n.T("sender.chatSelected", chatID, s.chats.mode),
+			map[string]interface{}{"chatID": chatID})
+	}
+	return idx, chatID
+}
+
+// resolveTargets заменяет псевдонимы целей на chat ID из AliasFile и логирует таблицу
+// соответствия. Если файл недоступен, цели остаются как есть
+func (s *Sender) resolveTargets(cfg *config.Config) []string {
+	targets := cfg.Targets()
+	if cfg.AliasFile == "" {
+		return targets
+	}
+	aliases, err := config.LoadAliases(cfg.AliasFile)
+	if err != nil {
+		s.log("error", CategoryRun, i18n.T("sender.aliasFileError", err))
+		return targets
+	}
+	for i, target := range targets {
+		if !config.IsChatAlias(target) {
+			continue
+		}
+		if id, ok := aliases[target]; ok {
+			s.log("info", CategoryRun, i18n.T("sender.aliasResolved", target, id))
+			targets[i] = id
+		} else {
+			s.log("warn", CategoryRun, i18n.T("sender.aliasUnknown", target))
+		}
+	}
+	return targets
+}
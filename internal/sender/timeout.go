@@ -0,0 +1,143 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// Виды таймаутов запроса в статистике (timeouts)
+const (
+	// TimeoutClient сработал http.Client.Timeout (Timeout) — одна попытка не уложилась
+	TimeoutClient = "client"
+	// TimeoutContext истёк срок контекста запроса (RequestTimeout) — запрос вместе с повторами не уложился
+	TimeoutContext = "context"
+	// TimeoutHeader не пришли заголовки ответа в срок HeaderTimeout
+	TimeoutHeader = "header"
+	// TimeoutCycleBudget исчерпан PerCycleBudget — отправка вместе с повторами не уложилась в бюджет цикла
+	TimeoutCycleBudget = "cycleBudget"
+)
+
+var (
+	errClientTimeout  = errors.New("истёк таймаут HTTP-клиента (timeout)")
+	errContextTimeout = errors.New("истёк срок запроса (requestTimeout)")
+	errCycleBudget    = errors.New("исчерпан бюджет цикла (perCycleBudget)")
+)
+
+// requestTimeout срок запроса вместе с повторами: RequestTimeout или, если не задан, Timeout
+func requestTimeout(cfg *config.Config) time.Duration {
+	if cfg.RequestTimeout > 0 {
+		return cfg.RequestTimeout
+	}
+	return cfg.Timeout
+}
+
+// requestTimeoutKey ключ контекста со сроком, выбранным для запроса
+type requestTimeoutKey struct{}
+
+// withRequestTimeout задаёт срок запроса requestNum. При заданных RequestTimeoutMin и
+// RequestTimeoutMax срок выбирается случайно из этого диапазона и пишется в лог — так
+// имитируются клиенты с разными таймаутами; иначе действует requestTimeout
+func (s *Sender) withRequestTimeout(ctx context.Context, requestNum int, cfg *config.Config) (context.Context, context.CancelFunc) {
+	limit := requestTimeout(cfg)
+	if cfg.RequestTimeoutMin > 0 && cfg.RequestTimeoutMax > 0 {
+		limit = cfg.RequestTimeoutMin + time.Duration(rand.Int63n(int64(cfg.RequestTimeoutMax-cfg.RequestTimeoutMin)+1))
+		s.logReq(requestNum, "info", CategoryRequest, i18n.T("sender.requestTimeoutPicked", limit),
+			map[string]interface{}{"timeoutMs": durationMs(limit)})
+	}
+	return context.WithTimeout(context.WithValue(ctx, requestTimeoutKey{}, limit), limit)
+}
+
+// requestTimeoutOf срок запроса, заданный withRequestTimeout, или requestTimeout
+func requestTimeoutOf(ctx context.Context, cfg *config.Config) time.Duration {
+	if limit, ok := ctx.Value(requestTimeoutKey{}).(time.Duration); ok {
+		return limit
+	}
+	return requestTimeout(cfg)
+}
+
+// withCycleBudget ограничивает отправку запроса, начатого в start, вместе со всеми повторами
+// сроком PerCycleBudget. Истечение срока помечается причиной errCycleBudget, чтобы отличать его
+// от RequestTimeout; без бюджета контекст возвращается как есть
+func withCycleBudget(ctx context.Context, start time.Time, cfg *config.Config) (context.Context, context.CancelFunc) {
+	if cfg.PerCycleBudget <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithDeadlineCause(ctx, start.Add(cfg.PerCycleBudget), errCycleBudget)
+}
+
+// cycleBudgetLeft сообщает, хватит ли бюджета цикла запроса, начатого в start, на паузу delay
+// перед следующей попыткой
+func cycleBudgetLeft(start time.Time, delay time.Duration, cfg *config.Config) bool {
+	return cfg.PerCycleBudget <= 0 || time.Until(start.Add(cfg.PerCycleBudget)) > delay
+}
+
+// cycleBudgetExhausted логирует исчерпание бюджета цикла перед попыткой attempt или во время
+// неё и помечает последнюю ошибку запроса
+func (s *Sender) cycleBudgetExhausted(requestNum, attempt int, err error) error {
+	if errors.Is(err, errCycleBudget) {
+		return err
+	}
+	budget := s.conf().PerCycleBudget
+	s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.cycleBudgetExhausted", budget, attempt+1),
+		map[string]interface{}{"timeout": TimeoutCycleBudget, "attempt": attempt + 1, "budgetMs": durationMs(budget)})
+	return fmt.Errorf("%w: %w", errCycleBudget, err)
+}
+
+// classifyTimeout различает таймаут HTTP-клиента и истечение срока контекста попытки ctx,
+// логирует, какой предел сработал, и помечает ошибку. Остальные и уже помеченные ошибки
+// возвращаются как есть. Оба таймаута net/http сообщает как net.Error с Timeout(), поэтому
+// сначала проверяется срок контекста: если он не истёк, таймаут поставил http.Client
+func (s *Sender) classifyTimeout(ctx context.Context, requestNum, attempt int, err error) error {
+	cfg := s.conf()
+	var netErr net.Error
+	switch {
+	case err == nil || timeoutKind(err) != "":
+		return err
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return s.contextTimeout(ctx, requestNum, attempt, err)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.clientTimeout", cfg.Timeout, attempt+1),
+			map[string]interface{}{"timeout": TimeoutClient, "attempt": attempt + 1, "timeoutMs": durationMs(cfg.Timeout)})
+		return fmt.Errorf("%w: %w", errClientTimeout, err)
+	}
+	return err
+}
+
+// contextTimeout логирует истечение срока запроса перед попыткой attempt или во время неё
+// и помечает последнюю ошибку запроса. Ошибка, уже помеченная сроком запроса или бюджетом
+// цикла, возвращается как есть: запрос логируется и учитывается одним таймаутом
+func (s *Sender) contextTimeout(ctx context.Context, requestNum, attempt int, err error) error {
+	if errors.Is(err, errContextTimeout) || errors.Is(err, errCycleBudget) {
+		return err
+	}
+	if errors.Is(context.Cause(ctx), errCycleBudget) {
+		return s.cycleBudgetExhausted(requestNum, attempt, err)
+	}
+	limit := requestTimeoutOf(ctx, s.conf())
+	s.logReq(requestNum, "warn", CategoryRequest, i18n.T("sender.contextTimeout", limit, attempt+1),
+		map[string]interface{}{"timeout": TimeoutContext, "attempt": attempt + 1, "timeoutMs": durationMs(limit)})
+	return fmt.Errorf("%w: %w", errContextTimeout, err)
+}
+
+// timeoutKind вид таймаута, которым завершился запрос; пустая строка — не таймаут.
+// Срок запроса проверяется первым: он может истечь в паузе после таймаута клиента
+func timeoutKind(err error) string {
+	switch {
+	case errors.Is(err, errCycleBudget):
+		return TimeoutCycleBudget
+	case errors.Is(err, errContextTimeout):
+		return TimeoutContext
+	case errors.Is(err, errClientTimeout):
+		return TimeoutClient
+	case errors.Is(err, errHeaderTimeout):
+		return TimeoutHeader
+	}
+	return ""
+}
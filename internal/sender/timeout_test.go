@@ -0,0 +1,110 @@
+package sender
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+func TestTimeoutClassification(t *testing.T) {
+	// Сервер отвечает только через секунду или когда клиент закрыл запрос
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		cfg  func(*config.Config)
+		want string
+	}{
+		{
+			name: "header",
+			cfg: func(cfg *config.Config) {
+				cfg.HeaderTimeout = 50 * time.Millisecond
+				cfg.RequestTimeout = 5 * time.Second
+			},
+			want: TimeoutHeader,
+		},
+		{
+			name: "client",
+			cfg: func(cfg *config.Config) {
+				cfg.Timeout = 50 * time.Millisecond
+				cfg.RequestTimeout = 5 * time.Second
+			},
+			want: TimeoutClient,
+		},
+		{
+			name: "context",
+			cfg: func(cfg *config.Config) {
+				cfg.RequestTimeout = 50 * time.Millisecond
+			},
+			want: TimeoutContext,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testSenderConfig()
+			tt.cfg(cfg)
+			client, err := telegram.NewClient(cfg.Timeout, "", false, "", func(string, string, telegram.LogMeta) {},
+				telegram.WithBaseURL(srv.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			logChan := make(chan LogEntry, 1024)
+			snd := NewSender(cfg, client, NewStats(), logChan)
+
+			start := time.Now()
+			ctx, cancel := snd.withRequestTimeout(context.Background(), 1, cfg)
+			defer cancel()
+			_, err = snd.sendWithRetry(ctx, 1, cfg.ChatID, start)
+			if got := timeoutKind(err); got != tt.want {
+				t.Fatalf("вид таймаута %q, ожидался %q (ошибка: %v)", got, tt.want, err)
+			}
+
+			// Таймаут клиента или срока запроса логируется один раз; таймаут заголовков
+			// пишется своим предупреждением и не должен попасть в них
+			close(logChan)
+			warnings := 0
+			for entry := range logChan {
+				if entry.Level == "warn" && entry.Fields["timeout"] != nil {
+					warnings++
+				}
+			}
+			want := 1
+			if tt.want == TimeoutHeader {
+				want = 0
+			}
+			if warnings != want {
+				t.Errorf("предупреждений о таймауте клиента или срока запроса %d, ожидалось %d", warnings, want)
+			}
+		})
+	}
+}
+
+func TestContextTimeoutMarksOnce(t *testing.T) {
+	logChan := make(chan LogEntry, 16)
+	snd := NewSender(testSenderConfig(), nil, NewStats(), logChan)
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+
+	err := snd.contextTimeout(ctx, 1, 0, context.DeadlineExceeded)
+	if again := snd.contextTimeout(ctx, 1, 1, err); again != err {
+		t.Errorf("повторная пометка изменила ошибку: %v", again)
+	}
+	if again := snd.classifyTimeout(ctx, 1, 1, err); again != err {
+		t.Errorf("classifyTimeout изменил помеченную ошибку: %v", again)
+	}
+	if n := len(logChan); n != 1 {
+		t.Errorf("записей о таймауте %d, ожидалась одна", n)
+	}
+}
@@ -0,0 +1,31 @@
+package sender
+
+import (
+	"strings"
+	"time"
+
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// timestampLayout формат отметки времени в сообщении (AppendTimestamp)
+const timestampLayout = "2006-01-02 15:04:05 MST"
+
+// markdownV2Escaper экранирует служебные символы MarkdownV2 обратной косой чертой
+var markdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`,
+	"`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`,
+	"{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// withTimestamp дописывает к тексту время t в поясе Timezone отдельной строкой. Отметка
+// добавляется в конец, чтобы не сдвигать смещения entities; для MarkdownV2 она экранируется
+func (s *Sender) withTimestamp(text string, t time.Time, opts telegram.MessageOptions) string {
+	if s.location == nil {
+		return text
+	}
+	stamp := t.In(s.location).Format(timestampLayout)
+	if opts.Markdown() {
+		stamp = markdownV2Escaper.Replace(stamp)
+	}
+	return text + "\n" + stamp
+}
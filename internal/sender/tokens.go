@@ -0,0 +1,40 @@
+package sender
+
+import (
+	"strings"
+
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// tokenIndex индекс токена бота для запроса requestNum: при нескольких токенах (BotTokens)
+// они чередуются по кругу, повторы запроса идут с тем же токеном. Вызовы вне запросов
+// (requestNum 0 — канарейка, проба) используют первый токен
+func (s *Sender) tokenIndex(requestNum int) int {
+	if len(s.tokens) <= 1 || requestNum <= 0 {
+		return 0
+	}
+	return (requestNum - 1) % len(s.tokens)
+}
+
+// requestToken токен бота из ротации для запроса requestNum; при ротации выбор пишется в лог
+func (s *Sender) requestToken(requestNum int) string {
+	token := s.tokens[s.tokenIndex(requestNum)]
+	if len(s.tokens) > 1 {
+		s.logReq(requestNum, "info", CategoryRequest, i18n.T("sender.tokenSelected", TokenLabel(token)),
+			map[string]interface{}{"bot": TokenLabel(token)})
+	}
+	return token
+}
+
+// limitsFor паузы после 429 для бота запроса requestNum: лимиты Telegram у каждого бота свои
+func (s *Sender) limitsFor(requestNum int) *rateLimits {
+	return s.limits[s.tokenIndex(requestNum)]
+}
+
+// TokenLabel обозначение токена для логов и статистики без секрета — ID бота (часть до двоеточия)
+func TokenLabel(token string) string {
+	if id, _, ok := strings.Cut(token, ":"); ok && id != "" {
+		return id
+	}
+	return "?"
+}
@@ -0,0 +1,30 @@
+package sender
+
+import (
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// warmup сообщает, относится ли запрос requestNum, начатый в start, к прогреву: прогрев
+// длится, пока не отправлено WarmupRequests запросов и не прошло WarmupDuration с начала
+// запуска (заданные условия должны выполниться оба)
+func (s *Sender) warmup(requestNum int, start time.Time) bool {
+	cfg := s.conf()
+	return (cfg.WarmupRequests > 0 && requestNum <= cfg.WarmupRequests) ||
+		(cfg.WarmupDuration > 0 && start.Sub(s.started) < cfg.WarmupDuration)
+}
+
+// bucket статистика, в которую попадает запрос: отдельная статистика прогрева или основная.
+// Первый запрос после прогрева пишет в лог начало измерения
+func (s *Sender) bucket(requestNum int, start time.Time) *Stats {
+	if s.warmup(requestNum, start) {
+		return s.stats.Warmup()
+	}
+	cfg := s.conf()
+	if (cfg.WarmupRequests > 0 || cfg.WarmupDuration > 0) && s.warmupDone.CompareAndSwap(false, true) {
+		s.logReq(requestNum, "info", CategoryRun, i18n.T("sender.warmupDone", s.stats.Warmup().Snapshot().Total, time.Since(s.started)),
+			map[string]interface{}{"warmup": false})
+	}
+	return s.stats
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/sender"
+)
+
+// autoStopCheckInterval период проверки, наблюдает ли кто-нибудь за запуском
+const autoStopCheckInterval = 5 * time.Second
+
+// watchUnobserved останавливает запуск, если дольше AutoStopAfterIdle к логам
+// не подключён ни один SSE-клиент (запуск забыт и впустую расходует лимиты)
+func (s *Server) watchUnobserved() {
+	ticker := time.NewTicker(autoStopCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.subMu.RLock()
+		watched := s.watchers() > 0
+		idleSince := s.unobservedSince
+		s.subMu.RUnlock()
+		if watched {
+			continue
+		}
+
+		s.mu.Lock()
+		limit := s.config.AutoStopAfterIdle
+		// Запуск без открытых логов (например, через API) считается ненаблюдаемым с момента старта
+		if s.runStarted.After(idleSince) {
+			idleSince = s.runStarted
+		}
+		if s.senderCancel != nil && limit > 0 && time.Since(idleSince) >= limit {
+			s.senderCancel(sender.StopCause(sender.StopUnobserved))
+			s.senderCancel = nil
+			s.sender = nil
+			s.log("warn", i18n.T("server.autoStopUnobserved", limit))
+			s.runID.Store("")
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/sender"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// BotWebhookResult состояние вебхука одного бота в ответе /api/bot/webhook
+type BotWebhookResult struct {
+	// Bot ID бота из токена
+	Bot     string                `json:"bot"`
+	Webhook *telegram.WebhookInfo `json:"webhook,omitempty"`
+	Deleted bool                  `json:"deleted,omitempty"`
+	Error   string                `json:"error,omitempty"`
+}
+
+// BotWebhook показывает вебхуки ботов из конфигурации (GET) или снимает их (DELETE,
+// ?dropPendingUpdates=true удаляет и накопленные обновления). При ротации BotTokens
+// обрабатывается каждый бот; ответ — список по ботам
+func (s *Server) BotWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.RLock()
+	cfg := *s.config
+	s.mu.RUnlock()
+	tokens := cfg.Tokens()
+	if len(tokens) == 0 {
+		http.Error(w, config.ErrBotTokenRequired.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logFunc := func(level, message string, meta telegram.LogMeta) {
+		s.logEntry(sender.NewLogEntry(level, message, meta))
+	}
+	client, err := telegram.NewClient(cfg.Timeout, cfg.ProxyURL, true, "", logFunc,
+		telegram.WithDNSServer(cfg.DNSServer), telegram.WithDoH(cfg.DoHEndpoint), telegram.WithLocalAddr(cfg.LocalAddr))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Ошибка создания клиента: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), cfg.Timeout)
+	defer cancel()
+
+	dropPending := r.URL.Query().Get("dropPendingUpdates") == "true"
+	results := make([]BotWebhookResult, 0, len(tokens))
+	for _, token := range tokens {
+		result := BotWebhookResult{Bot: sender.TokenLabel(token)}
+		if r.Method == http.MethodDelete {
+			err = client.DeleteWebhook(ctx, token, dropPending)
+			result.Deleted = err == nil
+			if err == nil {
+				s.log("info", i18n.T("server.botWebhookDeleted", result.Bot))
+			}
+		} else {
+			result.Webhook, err = client.GetWebhookInfo(ctx, token)
+		}
+		if err != nil {
+			result.Error = err.Error()
+			s.log("warn", i18n.T("server.botWebhookError", result.Bot, err))
+		}
+		results = append(results, result)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
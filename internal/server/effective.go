@@ -0,0 +1,81 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"SendMsgTestForTG/internal/config"
+)
+
+// Источники значений конфигурации в /api/config/effective. Переменных окружения и файла
+// конфигурации у сервера нет: файл (-config) читает только бенчмарк
+const (
+	ConfigSourceDefault = "default"
+	ConfigSourceAPI     = "api"
+	ConfigSourcePreset  = "preset"
+)
+
+// EffectiveConfig ответ /api/config/effective: действующая конфигурация без секретов
+// и источник каждого поля
+type EffectiveConfig struct {
+	Config *config.Config `json:"config"`
+	// Sources источник по JSON-имени поля: default, api или preset
+	Sources map[string]string `json:"sources"`
+}
+
+// effectiveConfig собирает действующую конфигурацию. Обновление через API и применение
+// пресета заменяют конфигурацию целиком, поэтому поле со значением по умолчанию (config.Default)
+// относится к default, а остальные — к последнему источнику замены
+func effectiveConfig(cfg *config.Config, source string) (EffectiveConfig, error) {
+	current, err := configFields(cfg)
+	if err != nil {
+		return EffectiveConfig{}, err
+	}
+	defaults, err := configFields(config.Default())
+	if err != nil {
+		return EffectiveConfig{}, err
+	}
+	sources := make(map[string]string, len(current))
+	for name, value := range current {
+		sources[name] = source
+		if bytes.Equal(value, defaults[name]) {
+			sources[name] = ConfigSourceDefault
+		}
+	}
+	return EffectiveConfig{Config: cfg.Redacted(), Sources: sources}, nil
+}
+
+// configFields JSON-значения полей конфигурации по именам
+func configFields(cfg *config.Config) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("конфигурация: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("конфигурация: %w", err)
+	}
+	return fields, nil
+}
+
+// GetEffectiveConfig возвращает действующую конфигурацию с источником каждого поля
+// и скрытыми токенами, секретом подписи и паролями прокси
+func (s *Server) GetEffectiveConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.RLock()
+	effective, err := effectiveConfig(s.config, s.configSource)
+	s.mu.RUnlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(effective)
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"SendMsgTestForTG/internal/sender"
+)
+
+// defaultSubscriberQueue размер очереди доставки подписчика, если SetSubscriberQueue не вызван
+const defaultSubscriberQueue = 256
+
+// SetSubscriberQueue задаёт размер очереди доставки каждого подписчика (-subscriber-queue);
+// действует для подписчиков, подключившихся после вызова
+func (s *Server) SetSubscriberQueue(size int) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	if size <= 0 {
+		size = defaultSubscriberQueue
+	}
+	s.subscriberQueue = size
+}
+
+// subscribe регистрирует подписчика, читающего из out, и запускает его горутину доставки.
+// Broadcaster кладёт записи только в очередь подписчика и никогда не ждёт её (deliverQueue),
+// а горутина переносит их в out по порядку. Медленный клиент задерживает лишь свою горутину,
+// переполнение его очереди теряет записи только у него.
+// Горутина завершается, когда закрыт done или очередь (unsubscribe), и закрывает out
+func (s *Server) subscribe(out chan sender.LogEntry, done chan struct{}) chan sender.LogEntry {
+	queue := make(chan sender.LogEntry, s.subscriberQueue)
+	s.subscribers[queue] = struct{}{}
+	go func() {
+		defer close(out)
+		for entry := range queue {
+			select {
+			case out <- entry:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return queue
+}
+
+// sseSink встроенный приёмник, раздающий записи лога SSE-подписчикам и файлу лога.
+// Итоги запросов он не принимает: они уже приходят записями категории result
+type sseSink struct {
+	s *Server
+}
+
+// RecordLog кладёт запись в очереди подписчиков, доставку выполняют их собственные горутины.
+// Потерянные при переполнении очередей записи учитываются в DroppedLogs
+func (sink sseSink) RecordLog(entry sender.LogEntry) {
+	s := sink.s
+	dropped := 0
+	s.subMu.RLock()
+	for queue := range s.subscribers {
+		dropped += deliverQueue(queue, entry)
+	}
+	s.subMu.RUnlock()
+	sender.CountDropped(dropped)
+}
+
+// deliverQueue кладёт запись в очередь подписчика по политике переполнения и возвращает число
+// потерянных записей. Политика block здесь не ждёт: полная очередь означает медленного
+// подписчика, и ожидание его остановило бы доставку всем остальным. Block по-прежнему
+// действует для основного канала логов, где broadcaster не успевает за отправителем
+func deliverQueue(queue chan sender.LogEntry, entry sender.LogEntry) int {
+	if sender.OverflowPolicy() == sender.OverflowBlock {
+		select {
+		case queue <- entry:
+			return 0
+		default:
+			return 1
+		}
+	}
+	return sender.Deliver(queue, entry)
+}
+
+// RecordResult ничего не делает: результат уже опубликован записью лога
+func (sseSink) RecordResult(sender.RequestEvent) {}
+
+// unsubscribe удаляет подписчика и закрывает его очередь; вызывается под subMu после close(done)
+func (s *Server) unsubscribe(queue chan sender.LogEntry) {
+	delete(s.subscribers, queue)
+	close(queue)
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"SendMsgTestForTG/internal/sender"
+)
+
+// addSubscribers подключает n подписчиков; с drain их записи сразу вычитываются
+func addSubscribers(s *Server, n int, drain bool) []chan struct{} {
+	var dones []chan struct{}
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for i := 0; i < n; i++ {
+		out := make(chan sender.LogEntry, 10)
+		done := make(chan struct{})
+		s.subscribe(out, done)
+		dones = append(dones, done)
+		if drain {
+			go func() {
+				for range out {
+				}
+			}()
+		}
+	}
+	return dones
+}
+
+func TestSlowSubscriberDoesNotBlockOthers(t *testing.T) {
+	sender.SetOverflowPolicy(sender.OverflowBlock)
+	defer sender.SetOverflowPolicy("")
+
+	s := NewServer()
+	s.SetSubscriberQueue(4)
+	// Медленный подписчик ничего не читает
+	for _, done := range addSubscribers(s, 1, false) {
+		defer close(done)
+	}
+	fast := make(chan sender.LogEntry, 100)
+	fastDone := make(chan struct{})
+	defer close(fastDone)
+	s.subMu.Lock()
+	s.subscribe(fast, fastDone)
+	s.subMu.Unlock()
+
+	before := sender.DroppedLogs()
+	const entries = 50
+	for i := 0; i < entries; i++ {
+		recorded := make(chan struct{})
+		go func() {
+			defer close(recorded)
+			sseSink{s}.RecordLog(sender.LogEntry{RequestNum: i + 1})
+		}()
+		select {
+		case <-recorded:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("запись %d: broadcaster ждёт медленного подписчика", i+1)
+		}
+		select {
+		case entry := <-fast:
+			if entry.RequestNum != i+1 {
+				t.Fatalf("запись %d пришла вместо %d", entry.RequestNum, i+1)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("быстрый подписчик не получил запись %d", i+1)
+		}
+	}
+	if sender.DroppedLogs() == before {
+		t.Error("записи, потерянные у медленного подписчика, не учтены в DroppedLogs")
+	}
+}
+
+func BenchmarkBroadcast100Subscribers(b *testing.B) {
+	s := NewServer()
+	for _, done := range addSubscribers(s, 100, true) {
+		defer close(done)
+	}
+	sink := sseSink{s}
+	entry := sender.LogEntry{Level: "info", Message: "benchmark", Category: sender.CategoryRun}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sink.RecordLog(entry)
+	}
+}
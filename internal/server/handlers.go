@@ -3,36 +3,117 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
 	"SendMsgTestForTG/internal/sender"
 	"SendMsgTestForTG/internal/telegram"
 )
 
 // Server представляет HTTP сервер
 type Server struct {
-	mu          sync.RWMutex
-	config      *config.Config
-	sender      *sender.Sender
-	senderCtx   context.Context
-	senderCancel context.CancelFunc
-	logChan     chan sender.LogEntry
-	subscribers map[chan sender.LogEntry]bool
+	mu           sync.RWMutex
+	config       *config.Config
+	sender       *sender.Sender
+	stats        *sender.Stats
+	senderCtx    context.Context
+	senderCancel context.CancelCauseFunc
+	logChan      chan sender.LogEntry
+	// subscribers очереди доставки подписчиков и их каналы завершения (subscribe)
+	subscribers map[chan sender.LogEntry]struct{}
 	subMu       sync.RWMutex
+	// subscriberQueue размер очереди доставки каждого подписчика (под subMu)
+	subscriberQueue int
+	history         *history
+	sinks           int
+	buildInfo       BuildInfo
+	// unobservedSince момент отключения последнего SSE-подписчика (под subMu)
+	unobservedSince time.Time
+	runStarted      time.Time
+	// runID идентификатор текущего запуска (string); пусто, когда отправка не запущена
+	runID atomic.Value
+	// presets сохранённые именованные конфигурации (/api/presets)
+	presets *presetStore
+	// writeTimeout срок записи ответа (-write-timeout); SSE-потоки применяют его к каждому событию
+	writeTimeout time.Duration
+	// preSendCommand единственная разрешённая команда перед отправкой (-pre-send-command); пусто — команды запрещены
+	preSendCommand string
+	// resultSinks приёмники результатов и логов; первый — SSE-подписчики (sseSink)
+	resultSinks []sender.ResultSink
+	// runDone закрывается, когда горутина текущего (или последнего) запуска полностью завершилась
+	runDone chan struct{}
+	// lastStopReason причина остановки последнего завершённого запуска (под mu)
+	lastStopReason string
+	// configSource откуда пришла текущая конфигурация: default, api или preset (под mu)
+	configSource string
+	// runLabel метка текущего или последнего запуска (RunLabel, под mu)
+	runLabel string
+	// starting запуск выполняет канарейку с отпущенной mu; другой Start в это время отклоняется (под mu)
+	starting bool
+	// runConfig конфигурация, по которой собраны клиент и отправитель текущего запуска (под mu)
+	runConfig *config.Config
+	// stdoutLevel и sseLevel пороги приёмников (config.LogLevelRank StdoutLogLevel и SSELogLevel)
+	stdoutLevel atomic.Int32
+	sseLevel    atomic.Int32
 }
 
 // NewServer создает новый HTTP сервер
 func NewServer() *Server {
 	logChan := make(chan sender.LogEntry, 100)
-	return &Server{
-		config:      config.Default(),
-		logChan:     logChan,
-		subscribers: make(map[chan sender.LogEntry]bool),
+	s := &Server{
+		config:          config.Default(),
+		configSource:    ConfigSourceDefault,
+		stats:           sender.NewStats(),
+		logChan:         logChan,
+		subscribers:     make(map[chan sender.LogEntry]struct{}),
+		subscriberQueue: defaultSubscriberQueue,
+		history:         newHistory(historySize),
+		presets:         newPresetStore(),
+		unobservedSince: time.Now(),
 	}
+	s.resultSinks = []sender.ResultSink{sseSink{s}}
+	s.applyLogLevels(s.config)
+	return s
+}
+
+// AddResultSink подключает приёмник результатов и логов: записи лога он получает из
+// broadcaster, итоги запросов — от отправителя каждого следующего запуска. Вызывается до
+// StartLogBroadcaster
+func (s *Server) AddResultSink(sink sender.ResultSink) {
+	s.resultSinks = append(s.resultSinks, sink)
+}
+
+// SetWriteTimeout задаёт срок записи, который SSE-потоки применяют к каждому событию вместо
+// WriteTimeout сервера; должен совпадать с WriteTimeout http.Server
+func (s *Server) SetWriteTimeout(d time.Duration) {
+	s.writeTimeout = d
+}
+
+// SetPreSendCommand разрешает команду перед отправкой cmd. Конфигурация из API и пресетов может
+// только повторить её или оставить preSendCommand пустым: иначе любой, кто достучится до
+// /api/config/update, выполнил бы произвольную команду оболочки на сервере
+func (s *Server) SetPreSendCommand(cmd string) {
+	s.preSendCommand = cmd
+}
+
+var errPreSendCommandNotAllowed = errors.New("preSendCommand задаётся только флагом -pre-send-command при запуске сервера")
+
+// checkPreSendCommand отклоняет команду перед отправкой, отличную от разрешённой SetPreSendCommand
+func (s *Server) checkPreSendCommand(cfg *config.Config) error {
+	if cfg.PreSendCommand != "" && cfg.PreSendCommand != s.preSendCommand {
+		return errPreSendCommandNotAllowed
+	}
+	return nil
 }
 
 // GetConfig возвращает текущую конфигурацию
@@ -54,8 +135,8 @@ func (s *Server) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var newConfig config.Config
-	if err := json.NewDecoder(r.Body).Decode(&newConfig); err != nil {
-		http.Error(w, fmt.Sprintf("Ошибка декодирования JSON: %v", err), http.StatusBadRequest)
+	if err := config.Decode(r.Body, &newConfig); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -63,17 +144,48 @@ func (s *Server) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if err := s.checkPreSendCommand(&newConfig); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
 
 	s.mu.Lock()
 	s.config = &newConfig
+	s.configSource = ConfigSourceAPI
+	// Работающий запуск получает новые значения со следующего запроса; поля, по которым собраны
+	// клиент и состояние отправителя, применятся только после перезапуска
+	running := s.sender != nil
+	var restartRequired []string
+	if running {
+		s.sender.SetConfig(&newConfig)
+		restartRequired = changedRestartOnly(s.runConfig, &newConfig)
+	}
 	s.mu.Unlock()
 
-	s.log("info", "Конфигурация обновлена")
+	sender.SetOverflowPolicy(newConfig.LogOverflowPolicy)
+	s.applyLogLevels(&newConfig)
+
+	s.log("info", i18n.T("server.configUpdated"))
+	if running {
+		s.log("info", i18n.T("server.configAppliedLive"))
+	}
+	if len(restartRequired) > 0 {
+		s.log("warn", i18n.T("server.configRestart", strings.Join(restartRequired, ", ")))
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	resp := map[string]interface{}{"status": "ok"}
+	if len(restartRequired) > 0 {
+		resp["restartRequired"] = restartRequired
+	}
+	json.NewEncoder(w).Encode(resp)
 }
 
+var (
+	errAlreadyRunning  = errors.New("Отправка уже запущена")
+	errStartInProgress = errors.New("Отправка уже запускается: выполняется канарейка")
+)
+
 // Start запускает отправку сообщений
 func (s *Server) Start(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -84,38 +196,149 @@ func (s *Server) Start(w http.ResponseWriter, r *http.Request) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.senderCancel != nil {
-		http.Error(w, "Отправка уже запущена", http.StatusBadRequest)
+	if s.starting {
+		http.Error(w, errStartInProgress.Error(), http.StatusConflict)
 		return
 	}
+	if s.senderCancel != nil {
+		if r.URL.Query().Get("restart") != "true" {
+			http.Error(w, errAlreadyRunning.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.stopForRestart(r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+	}
 
-	if err := s.config.Validate(); err != nil {
+	// Снимок конфигурации: пока блокировка отпущена на канарейку, её могут заменить
+	cfg := s.config
+	if err := cfg.Validate(); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	runID := sender.NewRunID()
 	// Создаём функцию логирования для клиента
-	logFunc := func(level, message string) {
-		s.log(level, message)
+	logFunc := func(level, message string, meta telegram.LogMeta) {
+		entry := sender.NewLogEntry(level, message, meta)
+		entry.RunID = runID
+		s.logEntry(entry)
 	}
 
-	client, err := telegram.NewClient(s.config.Timeout, s.config.ProxyURL, s.config.DisableKeepAlive, logFunc)
+	client, err := telegram.NewClient(cfg.Timeout, cfg.ProxyURL, cfg.DisableKeepAlive, cfg.RequestEncoding, logFunc,
+		telegram.WithRequestDump(cfg.LogRequestDump), telegram.WithRequestSigning(cfg.SigningHeader, cfg.SigningSecret),
+		telegram.WithDNSServer(cfg.DNSServer), telegram.WithDoH(cfg.DoHEndpoint), telegram.WithLocalAddr(cfg.LocalAddr),
+		telegram.WithMaxResponseBody(cfg.MaxResponseBody), telegram.WithTLSDetails(cfg.VerifyTLSDetails, cfg.TLSExpiryWarning),
+		telegram.WithMaxRequestsPerConn(cfg.MaxRequestsPerConn), telegram.WithTraceSummary(cfg.TraceSummary),
+		telegram.WithDiscardResponseBody(cfg.DiscardResponseBody), telegram.WithProxyPool(cfg.Proxies, cfg.ProxyStickiness))
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Ошибка создания клиента: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	s.senderCtx, s.senderCancel = context.WithCancel(context.Background())
-	s.sender = sender.NewSender(s.config, client, s.logChan)
-
-	go s.sender.Start(s.senderCtx)
+	stats := sender.NewStats()
+	snd := sender.NewSender(cfg, client, stats, s.logChan)
+	built := cfg
+	snd.SetRunID(runID)
+	for _, sink := range s.resultSinks {
+		snd.AddResultSink(sink)
+	}
+	// Канарейка выполняется синхронно: при ошибке запуск не начинается, статистика прошлого
+	// запуска сохраняется, а вызывающий получает причину в ответе
+	if cfg.Canary {
+		if err := s.runCanary(r.Context(), snd); err != nil {
+			status := http.StatusBadGateway
+			if errors.Is(err, errAlreadyRunning) {
+				status = http.StatusConflict
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		// Конфигурацию обновили во время канарейки: применяем, как к работающему запуску
+		if s.config != cfg {
+			cfg = s.config
+			snd.SetConfig(cfg)
+		}
+	}
 
-	s.log("info", "Отправка запущена")
+	s.runConfig = built
+	s.runStarted = time.Now()
+	s.runLabel = cfg.RunLabel
+	s.senderCtx, s.senderCancel = context.WithCancelCause(context.Background())
+	s.stats = stats
+	s.sender = snd
+	s.runID.Store(runID)
+
+	done := make(chan struct{})
+	s.runDone = done
+	go func(snd *sender.Sender, ctx context.Context, cfg *config.Config, stats *sender.Stats) {
+		defer close(done)
+		s.runSender(snd, runID, ctx, cfg, stats)
+	}(s.sender, s.senderCtx, cfg, s.stats)
+
+	var startFields map[string]interface{}
+	if cfg.RunLabel != "" {
+		startFields = map[string]interface{}{"runLabel": cfg.RunLabel}
+	}
+	s.lifecycle(runID, sender.EventRunStarted, "info", i18n.T("server.started"), startFields)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "started"})
 }
 
+// runCanary выполняет канарейку запуска без s.mu: сетевой запрос может идти до Timeout, и всё
+// это время Stop, статус и настройки должны отвечать. Пока блокировка отпущена, starting не даёт
+// начать другой запуск. Вызывается под s.mu
+func (s *Server) runCanary(ctx context.Context, snd *sender.Sender) error {
+	s.starting = true
+	s.mu.Unlock()
+	err := snd.Canary(ctx)
+	s.mu.Lock()
+	s.starting = false
+
+	if err != nil {
+		return err
+	}
+	// Пока блокировка была отпущена, запуск мог начать другой запрос
+	if s.senderCancel != nil {
+		return errAlreadyRunning
+	}
+	return nil
+}
+
+// stopForRestart останавливает текущий запуск для Start?restart=true и ждёт полного выхода его
+// горутины (включая очистку и итоговые события), чтобы два отправителя не писали в лог одновременно.
+// Вызывается под s.mu; на время ожидания блокировка отпускается
+func (s *Server) stopForRestart(ctx context.Context) error {
+	s.log("info", i18n.T("server.restarting"))
+	s.senderCancel(sender.StopCause(sender.StopRestart))
+	s.senderCancel = nil
+	s.sender = nil
+	done := s.runDone
+
+	s.mu.Unlock()
+	waitStart := time.Now()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = errors.New("перезапуск прерван: предыдущий запуск ещё завершается")
+	}
+	s.mu.Lock()
+
+	// Пока блокировка была отпущена, запуск мог начать другой запрос
+	if s.senderCancel != nil {
+		return errAlreadyRunning
+	}
+	s.runID.Store("")
+	if err != nil {
+		return err
+	}
+	s.log("info", i18n.T("server.restartWaited", time.Since(waitStart)))
+	return nil
+}
+
 // Stop останавливает отправку сообщений
 func (s *Server) Stop(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -124,54 +347,304 @@ func (s *Server) Stop(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	if s.senderCancel == nil {
+		s.mu.Unlock()
 		http.Error(w, "Отправка не запущена", http.StatusBadRequest)
 		return
 	}
 
-	s.senderCancel()
+	s.senderCancel(sender.StopCause(sender.StopManual))
+	snd := s.sender
 	s.senderCancel = nil
 	s.sender = nil
+	s.runID.Store("")
+	grace := s.config.StopGrace
+	s.mu.Unlock()
+
+	// С StopGrace ждём выхода цикла, чтобы быстрый Start после Stop не запустил второй отправитель
+	// параллельно. Ждём с отпущенной mu: статус, логи и настройки в это время доступны
+	if grace > 0 {
+		waitStart := time.Now()
+		select {
+		case <-snd.Done():
+			s.log("info", i18n.T("server.stopWaited", time.Since(waitStart)))
+		case <-time.After(grace):
+			s.log("warn", i18n.T("server.stopGraceOver", grace))
+		}
+	}
 
-	s.log("info", "Отправка остановлена")
+	s.log("info", i18n.T("server.stopped"))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "stopped"})
 }
 
-// GetStatus возвращает статус отправки
+// Shutdown останавливает текущий запуск с причиной StopSignal и ждёт, пока его горутина
+// завершится (итоговые события, отчёт, webhook), но не дольше ctx. Вызывается при завершении процесса
+func (s *Server) Shutdown(ctx context.Context) {
+	s.mu.Lock()
+	if s.senderCancel == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.senderCancel(sender.StopCause(sender.StopSignal))
+	s.senderCancel = nil
+	s.sender = nil
+	s.runID.Store("")
+	done := s.runDone
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Print(i18n.T("server.shutdownTimeout"))
+	}
+}
+
+// intervalRequest тело запроса /api/run/interval
+type intervalRequest struct {
+	Interval string `json:"interval"`
+}
+
+// SetInterval меняет интервал отправки работающего запуска без перезапуска
+func (s *Server) SetInterval(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req intervalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Ошибка декодирования JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	interval, err := time.ParseDuration(req.Interval)
+	if err != nil || interval <= 0 {
+		http.Error(w, "Интервал должен быть положительной длительностью, например \"500ms\"", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sender == nil {
+		http.Error(w, "Отправка не запущена", http.StatusBadRequest)
+		return
+	}
+	// Конфигурацию заменяем копией: прежний снимок может читать работающий отправитель
+	cfg := *s.config
+	cfg.Interval = interval
+	s.config = &cfg
+	s.sender.SetConfig(&cfg)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"interval": interval.String()})
+}
+
+// runSender выполняет запуск и по его завершении обрабатывает итоги
+func (s *Server) runSender(snd *sender.Sender, runID string, ctx context.Context, cfg *config.Config, stats *sender.Stats) {
+	started := time.Now()
+	reason := s.startRecovered(ctx, snd, runID)
+
+	s.mu.Lock()
+	s.lastStopReason = reason
+	// Запуск завершился сам (не через Stop) — сбрасываем состояние сервера
+	selfStopped := s.sender == snd
+	if selfStopped {
+		s.senderCancel(sender.StopCause(reason))
+		s.senderCancel = nil
+		s.sender = nil
+		s.runID.Store("")
+	}
+	s.mu.Unlock()
+
+	event, message := sender.EventRunStopped, i18n.T("server.runStopped", reason)
+	if selfStopped {
+		event, message = sender.EventRunCompleted, i18n.T("server.runCompleted", reason)
+	}
+	level := "info"
+	if reason == sender.StopPanic {
+		level = "error"
+	}
+	s.lifecycle(runID, event, level, message, map[string]interface{}{
+		"reason": reason,
+		"stats":  stats.Snapshot(),
+	})
+
+	if cfg.ReportFile != "" {
+		report := sender.NewReport(runID, reason, started, time.Now(), cfg, stats.Snapshot())
+		if err := sender.WriteReport(cfg.ReportFile, report); err != nil {
+			s.log("error", i18n.T("server.reportError", err))
+		} else {
+			s.log("info", i18n.T("server.reportWritten", cfg.ReportFile))
+		}
+	}
+
+	if cfg.CompletionWebhook != "" {
+		s.sendCompletionWebhook(cfg.CompletionWebhook, RunSummary{
+			RunID:    runID,
+			RunLabel: cfg.RunLabel,
+			Reason:   reason,
+			Started:  started,
+			Finished: time.Now(),
+			Stats:    stats.Snapshot(),
+		})
+	}
+}
+
+// startRecovered выполняет запуск и перехватывает панику отправителя: она пишется в лог со стеком,
+// а запуск завершается с причиной panic, как обычная остановка — с итоговым событием, отчётом
+// и сбросом состояния сервера. Без этого паника в горутине завершила бы весь процесс
+func (s *Server) startRecovered(ctx context.Context, snd *sender.Sender, runID string) (reason string) {
+	defer func() {
+		if p := recover(); p != nil {
+			reason = sender.StopPanic
+			s.logEntry(sender.LogEntry{
+				RunID:    runID,
+				Time:     time.Now(),
+				Level:    "error",
+				Message:  i18n.T("server.senderPanic", p),
+				Category: sender.CategoryRun,
+				Fields:   map[string]interface{}{"panic": fmt.Sprint(p), "stack": string(debug.Stack())},
+			})
+		}
+	}()
+	return snd.Start(ctx)
+}
+
+// GetStatus возвращает статус отправки и причину остановки последнего запуска
 func (s *Server) GetStatus(w http.ResponseWriter, r *http.Request) {
 	s.mu.RLock()
 	isRunning := s.senderCancel != nil
+	lastStopReason := s.lastStopReason
+	runLabel := s.runLabel
 	s.mu.RUnlock()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"running": isRunning,
+		"running":        isRunning,
+		"runId":          s.currentRunID(),
+		"runLabel":       runLabel,
+		"droppedLogs":    sender.DroppedLogs(),
+		"lastStopReason": lastStopReason,
 	})
 }
 
+// GetStats возвращает статистику текущего (или последнего) запуска
+func (s *Server) GetStats(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	stats := s.stats
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats.Snapshot())
+}
+
 // LogsSSE отправляет логи через Server-Sent Events
 func (s *Server) LogsSSE(w http.ResponseWriter, r *http.Request) {
+	s.streamSSE(w, r, func(e sender.LogEntry) bool { return passesLevel(e, s.sseLevel.Load()) })
+}
+
+// EventsSSE отправляет через SSE только итоги запросов и события жизненного цикла, без трассировки
+func (s *Server) EventsSSE(w http.ResponseWriter, r *http.Request) {
+	s.streamSSE(w, r, isRunEvent)
+}
+
+// ConnsSSE отправляет через SSE только события жизни соединений (conn_open, conn_reuse, conn_close)
+func (s *Server) ConnsSSE(w http.ResponseWriter, r *http.Request) {
+	s.streamSSE(w, r, isConnEvent)
+}
+
+// isConnEvent отбирает записи для /api/conns
+func isConnEvent(e sender.LogEntry) bool {
+	return e.Category == telegram.CategoryConnEvent
+}
+
+// isRunEvent отбирает записи для /api/events: итоги запросов (категория result) и события запуска
+func isRunEvent(e sender.LogEntry) bool {
+	return e.Category == sender.CategoryResult || e.Type != ""
+}
+
+// streamSSE подписывает клиента на поток логов; filter, если задан, отбирает отправляемые записи.
+// Отфильтрованные записи всё равно вычитываются, чтобы подписчик не тормозил broadcaster
+func (s *Server) streamSSE(w http.ResponseWriter, r *http.Request, filter func(sender.LogEntry) bool) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
+	// Переподключившийся клиент присылает ID последнего полученного события: заголовок
+	// Last-Event-ID (при автоматическом переподключении EventSource) или параметр lastEventId
+	lastID, _ := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64)
+	if lastID == 0 {
+		lastID, _ = strconv.ParseInt(r.URL.Query().Get("lastEventId"), 10, 64)
+	}
+
 	subChan := make(chan sender.LogEntry, 10)
+	subDone := make(chan struct{})
 	s.subMu.Lock()
-	s.subscribers[subChan] = true
+	queue := s.subscribe(subChan, subDone)
+	// Снимок берём под блокировкой подписчиков: все следующие события придут через subChan
+	var replay []sender.LogEntry
+	if lastID > 0 {
+		replay, lastID = s.history.since(lastID)
+	}
 	s.subMu.Unlock()
 
 	defer func() {
+		// Сначала останавливаем горутину доставки, если она ждёт записи в subChan
+		close(subDone)
 		s.subMu.Lock()
-		delete(s.subscribers, subChan)
-		close(subChan)
+		s.unsubscribe(queue)
+		if s.watchers() == 0 {
+			s.unobservedSince = time.Now()
+		}
 		s.subMu.Unlock()
 	}()
 
+	// WriteTimeout сервера оборвал бы бессрочный поток, поэтому срок записи задаётся заново
+	// перед каждой порцией: поток живёт сколько угодно, а клиент, который не принимает
+	// данные дольше writeTimeout, отключается и не держит горутину.
+	// Ошибка записи или Flush (клиент отключился посреди порции) завершает поток сразу:
+	// дальнейшие записи в оборванное соединение бесполезны, а подписчик освобождается
+	// в defer, не дожидаясь отмены контекста запроса
+	rc := http.NewResponseController(w)
+	send := func(write func() error) bool {
+		var deadline time.Time
+		if s.writeTimeout > 0 {
+			deadline = time.Now().Add(s.writeTimeout)
+		}
+		rc.SetWriteDeadline(deadline)
+		if write() != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	s.mu.RLock()
+	retry := s.config.SSERetry
+	s.mu.RUnlock()
+
+	ok := send(func() error {
+		if retry > 0 {
+			if _, err := fmt.Fprintf(w, "retry: %d\n\n", retry.Milliseconds()); err != nil {
+				return err
+			}
+		}
+		for _, logEntry := range replay {
+			if filter == nil || filter(logEntry) {
+				if err := writeEvent(w, logEntry); err != nil {
+					return err
+				}
+			}
+			lastID = logEntry.ID
+		}
+		return nil
+	})
+	if !ok {
+		return
+	}
+
 	ctx := r.Context()
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
@@ -181,47 +654,126 @@ func (s *Server) LogsSSE(w http.ResponseWriter, r *http.Request) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			fmt.Fprintf(w, "data: {\"type\":\"ping\"}\n\n")
-			w.(http.Flusher).Flush()
+			if !send(func() error {
+				_, err := fmt.Fprintf(w, "data: {\"type\":\"ping\"}\n\n")
+				return err
+			}) {
+				return
+			}
 		case logEntry := <-subChan:
-			data, err := json.Marshal(logEntry)
-			if err != nil {
+			// Снимок статистики вне истории: без ID, на lastID не влияет
+			if logEntry.ID == 0 {
+				if (filter == nil || filter(logEntry)) && !send(func() error { return writeEvent(w, logEntry) }) {
+					return
+				}
+				continue
+			}
+			// Событие могло попасть и в снимок истории, и в канал — повтор пропускаем
+			if logEntry.ID <= lastID {
 				continue
 			}
-			fmt.Fprintf(w, "data: %s\n\n", data)
-			w.(http.Flusher).Flush()
+			lastID = logEntry.ID
+			if filter != nil && !filter(logEntry) {
+				continue
+			}
+			if !send(func() error { return writeEvent(w, logEntry) }) {
+				return
+			}
 		}
 	}
 }
 
-// log отправляет запись в канал логов (broadcaster разошлёт подписчикам)
-func (s *Server) log(level, message string) {
-	entry := sender.LogEntry{
-		Time:    time.Now(),
-		Level:   level,
-		Message: message,
+// writeEvent записывает запись лога как SSE-событие с её ID; записи без ID (снимки
+// статистики) отправляются без поля id, чтобы не сбивать Last-Event-ID клиента.
+// Возвращает ошибку записи; запись, которую не удалось закодировать, пропускается
+func writeEvent(w http.ResponseWriter, logEntry sender.LogEntry) error {
+	data, err := json.Marshal(logEntry)
+	if err != nil {
+		return nil
 	}
+	if logEntry.ID == 0 {
+		_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", logEntry.ID, data)
+	return err
+}
 
-	select {
-	case s.logChan <- entry:
-	default:
-		// Канал переполнен, пропускаем
+// reportDroppedLogs периодически предупреждает о записях, потерянных из-за переполнения канала
+func (s *Server) reportDroppedLogs() {
+	ticker := time.NewTicker(droppedReportInterval)
+	defer ticker.Stop()
+
+	var reported int64
+	for range ticker.C {
+		dropped := sender.DroppedLogs()
+		if dropped == reported {
+			continue
+		}
+		s.logEntry(sender.LogEntry{
+			Time:     time.Now(),
+			Level:    "warn",
+			Message:  i18n.T("server.droppedLogs", dropped-reported, dropped),
+			Category: sender.CategoryRun,
+			Fields:   map[string]interface{}{"dropped": dropped - reported, "droppedTotal": dropped},
+		})
+		reported = dropped
 	}
 }
 
+// log отправляет запись в канал логов (broadcaster разошлёт подписчикам)
+func (s *Server) log(level, message string) {
+	s.logEntry(sender.LogEntry{
+		RunID:    s.currentRunID(),
+		Time:     time.Now(),
+		Level:    level,
+		Message:  message,
+		Category: sender.CategoryRun,
+	})
+}
+
+// lifecycle отправляет в поток логов событие жизненного цикла запуска runID
+func (s *Server) lifecycle(runID, eventType, level, message string, fields map[string]interface{}) {
+	s.logEntry(sender.LogEntry{
+		RunID:    runID,
+		Time:     time.Now(),
+		Type:     eventType,
+		Level:    level,
+		Message:  message,
+		Category: sender.CategoryRun,
+		Fields:   fields,
+	})
+}
+
+// currentRunID возвращает идентификатор текущего запуска или пустую строку
+func (s *Server) currentRunID() string {
+	id, _ := s.runID.Load().(string)
+	return id
+}
+
+// logEntry отправляет готовую запись в канал логов
+func (s *Server) logEntry(entry sender.LogEntry) {
+	sender.Emit(s.logChan, entry)
+}
+
+// droppedReportInterval период проверки счётчика пропущенных записей лога
+const droppedReportInterval = 5 * time.Second
+
 // StartLogBroadcaster запускает широковещатель логов
 func (s *Server) StartLogBroadcaster() {
+	go s.reportDroppedLogs()
+	go s.watchUnobserved()
+	go s.broadcastStats()
+
 	go func() {
 		for entry := range s.logChan {
-			s.subMu.RLock()
-			for subChan := range s.subscribers {
-				select {
-				case subChan <- entry:
-				default:
-				}
+			// Снимки статистики не нумеруются и не вытесняют записи из истории
+			if entry.Type != sender.EventStats {
+				entry = s.history.add(entry)
+			}
+			for _, sink := range s.resultSinks {
+				sink.RecordLog(entry)
 			}
-			s.subMu.RUnlock()
 		}
 	}()
 }
-
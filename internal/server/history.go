@@ -0,0 +1,59 @@
+package server
+
+import (
+	"sync"
+
+	"SendMsgTestForTG/internal/sender"
+)
+
+// historySize число последних событий лога, хранимых для повтора после переподключения SSE
+const historySize = 1000
+
+// history кольцевой буфер последних событий лога с монотонно растущими ID
+type history struct {
+	mu      sync.Mutex
+	entries []sender.LogEntry
+	next    int
+	lastID  int64
+}
+
+// newHistory создаёт буфер на size событий
+func newHistory(size int) *history {
+	return &history{entries: make([]sender.LogEntry, 0, size)}
+}
+
+// add присваивает записи следующий ID, сохраняет её и возвращает с ID
+func (h *history) add(entry sender.LogEntry) sender.LogEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	entry.ID = h.lastID
+	if len(h.entries) < cap(h.entries) {
+		h.entries = append(h.entries, entry)
+	} else {
+		h.entries[h.next] = entry
+		h.next = (h.next + 1) % len(h.entries)
+	}
+	return entry
+}
+
+// since возвращает хранимые события с ID больше lastID в порядке поступления и lastID,
+// от которого их следует отсчитывать. ID из будущего (клиент пережил перезапуск сервера)
+// сбрасывается в 0, чтобы новые события не отбрасывались как повторы
+func (h *history) since(lastID int64) ([]sender.LogEntry, int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if lastID > h.lastID {
+		lastID = 0
+	}
+	var result []sender.LogEntry
+	for i := 0; i < len(h.entries); i++ {
+		entry := h.entries[(h.next+i)%len(h.entries)]
+		if entry.ID > lastID {
+			result = append(result, entry)
+		}
+	}
+	return result, lastID
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"reflect"
+
+	"SendMsgTestForTG/internal/config"
+)
+
+// restartOnlyFields поля конфигурации, которые работающий запуск не подхватывает через
+// Sender.SetConfig: по ним один раз собираются клиент (Start) и состояние отправителя
+// (NewSender, Sender.Start)
+var restartOnlyFields = []struct {
+	name  string
+	value func(c *config.Config) any
+}{
+	// Клиент Telegram
+	{"proxyURL", func(c *config.Config) any { return c.ProxyURL }},
+	{"proxies", func(c *config.Config) any { return c.Proxies }},
+	{"proxyStickiness", func(c *config.Config) any { return c.ProxyStickiness }},
+	{"timeout", func(c *config.Config) any { return c.Timeout }},
+	{"disableKeepAlive", func(c *config.Config) any { return c.DisableKeepAlive }},
+	{"maxRequestsPerConn", func(c *config.Config) any { return c.MaxRequestsPerConn }},
+	{"requestEncoding", func(c *config.Config) any { return c.RequestEncoding }},
+	{"dnsServer", func(c *config.Config) any { return c.DNSServer }},
+	{"dohEndpoint", func(c *config.Config) any { return c.DoHEndpoint }},
+	{"localAddr", func(c *config.Config) any { return c.LocalAddr }},
+	{"maxResponseBody", func(c *config.Config) any { return c.MaxResponseBody }},
+	{"discardResponseBody", func(c *config.Config) any { return c.DiscardResponseBody }},
+	{"verifyTLSDetails", func(c *config.Config) any { return c.VerifyTLSDetails }},
+	{"tlsExpiryWarning", func(c *config.Config) any { return c.TLSExpiryWarning }},
+	{"signingHeader", func(c *config.Config) any { return c.SigningHeader }},
+	{"signingSecret", func(c *config.Config) any { return c.SigningSecret }},
+	{"logRequestDump", func(c *config.Config) any { return c.LogRequestDump }},
+	{"traceSummary", func(c *config.Config) any { return c.TraceSummary }},
+	// Отправитель
+	{"botTokens", func(c *config.Config) any { return c.Tokens() }},
+	{"chatIDs", func(c *config.Config) any { return c.Targets() }},
+	{"chatSelection", func(c *config.Config) any { return c.ChatSelection }},
+	{"aliasFile", func(c *config.Config) any { return c.AliasFile }},
+	{"retryRate", func(c *config.Config) any { return c.RetryRate }},
+	{"retryBurst", func(c *config.Config) any { return c.RetryBurst }},
+	{"maxBandwidth", func(c *config.Config) any { return c.MaxBandwidth }},
+	{"appendTimestamp", func(c *config.Config) any { return c.AppendTimestamp }},
+	{"timezone", func(c *config.Config) any { return c.Timezone }},
+	{"summaryInterval", func(c *config.Config) any { return c.SummaryInterval }},
+	{"rampTest", func(c *config.Config) any { return c.RampTest }},
+	{"targetLatency", func(c *config.Config) any { return c.TargetLatency }},
+}
+
+// changedRestartOnly возвращает имена полей из restartOnlyFields, которые отличаются в next от cur
+func changedRestartOnly(cur, next *config.Config) []string {
+	var changed []string
+	for _, f := range restartOnlyFields {
+		if !reflect.DeepEqual(f.value(cur), f.value(next)) {
+			changed = append(changed, f.name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"SendMsgTestForTG/internal/config"
+
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/sender"
+)
+
+// sinkBuffer размер буфера подписки файлового приёмника: запись на диск может подтормаживать
+const sinkBuffer = 1000
+
+// AddLogSink подписывает w на все записи лога через broadcaster; каждая запись пишется
+// JSON-строкой. Приёмник не считается наблюдателем для автоостановки; снимки статистики
+// (EventStats) в файл не пишутся
+func (s *Server) AddLogSink(w io.Writer) {
+	subChan := make(chan sender.LogEntry, sinkBuffer)
+	s.subMu.Lock()
+	s.subscribe(subChan, make(chan struct{}))
+	s.sinks++
+	s.subMu.Unlock()
+
+	go func() {
+		enc := json.NewEncoder(w)
+		for entry := range subChan {
+			if entry.Type == sender.EventStats {
+				continue
+			}
+			if err := enc.Encode(entry); err != nil {
+				log.Print(i18n.T("server.logSinkError", err))
+			}
+		}
+	}()
+}
+
+// AddStdoutSink выводит в w записи лога не ниже порога StdoutLogLevel читаемыми строками,
+// как консоль бенчмарка. Порог меняется вместе с конфигурацией; пустой — off, чтобы вывод
+// процесса по умолчанию оставался прежним. Как и файл лога, наблюдателем не считается
+func (s *Server) AddStdoutSink(w io.Writer) {
+	subChan := make(chan sender.LogEntry, sinkBuffer)
+	s.subMu.Lock()
+	s.subscribe(subChan, make(chan struct{}))
+	s.sinks++
+	s.subMu.Unlock()
+
+	go func() {
+		for entry := range subChan {
+			if entry.Type == sender.EventStats || !passesLevel(entry, s.stdoutLevel.Load()) {
+				continue
+			}
+			prefix := ""
+			if entry.RequestNum > 0 {
+				prefix = fmt.Sprintf("#%d ", entry.RequestNum)
+			}
+			fmt.Fprintf(w, "%s [%s] %s%s\n", entry.Time.Format("15:04:05.000"), strings.ToUpper(entry.Level), prefix, entry.Message)
+		}
+	}()
+}
+
+// applyLogLevels запоминает пороги stdout и SSE из конфигурации; пустой StdoutLogLevel — off,
+// пустой SSELogLevel — info (все записи)
+func (s *Server) applyLogLevels(cfg *config.Config) {
+	stdout := cfg.StdoutLogLevel
+	if stdout == "" {
+		stdout = config.LogLevelOff
+	}
+	s.stdoutLevel.Store(int32(config.LogLevelRank(stdout)))
+	s.sseLevel.Store(int32(config.LogLevelRank(cfg.SSELogLevel)))
+}
+
+// passesLevel сообщает, проходит ли запись порог minRank. События запуска и снимки статистики
+// проходят любой порог, кроме off: по ним веб-интерфейс отслеживает состояние запуска
+func passesLevel(e sender.LogEntry, minRank int32) bool {
+	if minRank >= int32(config.LogLevelRank(config.LogLevelOff)) {
+		return false
+	}
+	return e.Type != "" || int32(config.LogLevelRank(e.Level)) >= minRank
+}
+
+// watchers возвращает число подключённых SSE-клиентов (без файловых приёмников); вызывается под subMu
+func (s *Server) watchers() int {
+	return len(s.subscribers) - s.sinks
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"SendMsgTestForTG/internal/sender"
+)
+
+// metricsQuantiles перцентили этапов в экспозиции и соответствующие поля PhaseStats
+var metricsQuantiles = []struct {
+	label string
+	value func(sender.PhaseStats) float64
+}{
+	{"0.5", func(p sender.PhaseStats) float64 { return p.P50Ms }},
+	{"0.9", func(p sender.PhaseStats) float64 { return p.P90Ms }},
+	{"0.95", func(p sender.PhaseStats) float64 { return p.P95Ms }},
+	{"0.99", func(p sender.PhaseStats) float64 { return p.P99Ms }},
+}
+
+// labelEscaper экранирует значения меток по правилам текстового формата Prometheus
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Metrics отдаёт статистику текущего (или последнего) запуска в текстовом формате Prometheus.
+// Счётчики сбрасываются с каждым запуском; идентификатор запуска — в метрике tgtester_run_info
+func (s *Server) Metrics(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	stats := s.stats
+	running := s.senderCancel != nil
+	s.mu.RUnlock()
+	snap := stats.Snapshot()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	metricHeader(w, "tgtester_running", "gauge", "Идёт ли отправка (1) или нет (0)")
+	fmt.Fprintf(w, "tgtester_running %d\n", boolMetric(running))
+	if snap.RunID != "" {
+		metricHeader(w, "tgtester_run_info", "gauge", "Идентификатор текущего или последнего запуска")
+		fmt.Fprintf(w, "tgtester_run_info{run_id=\"%s\"} 1\n", labelEscaper.Replace(snap.RunID))
+	}
+	metricHeader(w, "tgtester_run_start_time_seconds", "gauge", "Время начала запуска, Unix-время")
+	fmt.Fprintf(w, "tgtester_run_start_time_seconds %d\n", snap.Started.Unix())
+
+	metricHeader(w, "tgtester_requests_total", "counter", "Запросы за запуск по результату")
+	fmt.Fprintf(w, "tgtester_requests_total{result=\"success\"} %d\n", snap.Success)
+	fmt.Fprintf(w, "tgtester_requests_total{result=\"error\"} %d\n", snap.Errors)
+
+	metricHeader(w, "tgtester_errors_total", "counter", "Ошибки запросов по классам (4xx, 429, 5xx, network, injected, other)")
+	classes := make([]string, 0, len(snap.ErrorClasses))
+	for class := range snap.ErrorClasses {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		fmt.Fprintf(w, "tgtester_errors_total{class=\"%s\"} %d\n", class, snap.ErrorClasses[class])
+	}
+
+	metricHeader(w, "tgtester_timeouts_total", "counter", "Запросы, завершившиеся таймаутом, по видам (client, context, header)")
+	kinds := make([]string, 0, len(snap.Timeouts))
+	for kind := range snap.Timeouts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(w, "tgtester_timeouts_total{kind=\"%s\"} %d\n", kind, snap.Timeouts[kind])
+	}
+
+	metricHeader(w, "tgtester_chat_requests_total", "counter", "Запросы за запуск по чатам и результату")
+	chats := make([]string, 0, len(snap.Chats))
+	for id := range snap.Chats {
+		chats = append(chats, id)
+	}
+	sort.Strings(chats)
+	for _, id := range chats {
+		chat := labelEscaper.Replace(id)
+		fmt.Fprintf(w, "tgtester_chat_requests_total{chat_id=\"%s\",result=\"success\"} %d\n", chat, snap.Chats[id].Success)
+		fmt.Fprintf(w, "tgtester_chat_requests_total{chat_id=\"%s\",result=\"error\"} %d\n", chat, snap.Chats[id].Errors)
+	}
+
+	metricHeader(w, "tgtester_retries_total", "counter", "Повторы запросов: выполненные и отклонённые бюджетом")
+	fmt.Fprintf(w, "tgtester_retries_total{outcome=\"done\"} %d\n", snap.Retries)
+	fmt.Fprintf(w, "tgtester_retries_total{outcome=\"denied\"} %d\n", snap.RetriesDenied)
+
+	metricHeader(w, "tgtester_edits_total", "counter", "Изменения сообщений по результату")
+	fmt.Fprintf(w, "tgtester_edits_total{result=\"success\"} %d\n", snap.Edits-snap.EditErrors)
+	fmt.Fprintf(w, "tgtester_edits_total{result=\"error\"} %d\n", snap.EditErrors)
+
+	metricHeader(w, "tgtester_duplicates_total", "counter", "Повторно отправленные тексты (detectDuplicates)")
+	fmt.Fprintf(w, "tgtester_duplicates_total %d\n", snap.Duplicates)
+
+	metricHeader(w, "tgtester_sent_bytes_total", "counter", "Отправлено байт: тела и оценка заголовков")
+	fmt.Fprintf(w, "tgtester_sent_bytes_total %d\n", snap.BytesSent)
+	metricHeader(w, "tgtester_received_bytes_total", "counter", "Получено байт: тела и оценка заголовков")
+	fmt.Fprintf(w, "tgtester_received_bytes_total %d\n", snap.BytesReceived)
+
+	metricHeader(w, "tgtester_dropped_logs_total", "counter", "Логи, отброшенные при переполнении очереди")
+	fmt.Fprintf(w, "tgtester_dropped_logs_total %d\n", sender.DroppedLogs())
+
+	// Перцентили считаются по окну последних замеров, сумма — по среднему за запуск
+	metricHeader(w, "tgtester_phase_duration_seconds", "summary", "Длительность этапов запроса")
+	phases := make([]string, 0, len(snap.Phases))
+	for name := range snap.Phases {
+		phases = append(phases, name)
+	}
+	sort.Strings(phases)
+	for _, name := range phases {
+		p := snap.Phases[name]
+		for _, q := range metricsQuantiles {
+			fmt.Fprintf(w, "tgtester_phase_duration_seconds{phase=\"%s\",quantile=\"%s\"} %g\n", name, q.label, q.value(p)/1000)
+		}
+		fmt.Fprintf(w, "tgtester_phase_duration_seconds_sum{phase=\"%s\"} %g\n", name, p.AvgMs*float64(p.Count)/1000)
+		fmt.Fprintf(w, "tgtester_phase_duration_seconds_count{phase=\"%s\"} %d\n", name, p.Count)
+	}
+}
+
+// metricHeader пишет строки HELP и TYPE метрики
+func metricHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// boolMetric переводит флаг в значение метрики
+func boolMetric(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"SendMsgTestForTG/internal/config"
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/sender"
+)
+
+var (
+	errPresetName     = errors.New("имя пресета не может быть пустым или содержать \"/\"")
+	errPresetNotFound = errors.New("пресет не найден")
+)
+
+// presetStore именованные конфигурации. При заданном path набор хранится в JSON-файле
+// {"имя": конфигурация} и перезаписывается при каждом сохранении; без path — только в памяти
+type presetStore struct {
+	mu      sync.Mutex
+	path    string
+	presets map[string]*config.Config
+}
+
+// newPresetStore создаёт пустой набор пресетов в памяти
+func newPresetStore() *presetStore {
+	return &presetStore{presets: make(map[string]*config.Config)}
+}
+
+// load читает пресеты из файла path и дальше сохраняет их туда же. Отсутствующий файл —
+// пустой набор, он будет создан при первом сохранении
+func (p *presetStore) load(path string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.path = path
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("пресеты: %w", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("пресеты: %w", err)
+	}
+	for name, body := range raw {
+		var cfg config.Config
+		if err := config.Decode(bytes.NewReader(body), &cfg); err != nil {
+			return fmt.Errorf("пресет %q: %w", name, err)
+		}
+		p.presets[name] = &cfg
+	}
+	return nil
+}
+
+// save добавляет или заменяет пресет name и записывает набор в файл
+func (p *presetStore) save(name string, cfg *config.Config) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.presets[name] = cfg
+	if p.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(p.presets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("пресеты: %w", err)
+	}
+	// Как и отчёт запуска, файл заменяется переименованием, чтобы не остаться записанным наполовину
+	tmp, err := os.CreateTemp(filepath.Dir(p.path), filepath.Base(p.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("пресеты: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("пресеты: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("пресеты: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p.path); err != nil {
+		return fmt.Errorf("пресеты: %w", err)
+	}
+	return nil
+}
+
+// get возвращает пресет name
+func (p *presetStore) get(name string) (*config.Config, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cfg, ok := p.presets[name]
+	return cfg, ok
+}
+
+// Preset пресет в ответе /api/presets
+type Preset struct {
+	Name   string         `json:"name"`
+	Config *config.Config `json:"config"`
+}
+
+// list пресеты, отсортированные по имени
+func (p *presetStore) list() []Preset {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	list := make([]Preset, 0, len(p.presets))
+	for name, cfg := range p.presets {
+		list = append(list, Preset{Name: name, Config: cfg})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// LoadPresets подключает файл пресетов path: пресеты читаются из него и сохраняются в него
+func (s *Server) LoadPresets(path string) error {
+	return s.presets.load(path)
+}
+
+// presetRequest тело POST /api/presets
+type presetRequest struct {
+	Name string `json:"name"`
+}
+
+// Presets возвращает список пресетов (GET) или сохраняет текущую конфигурацию под именем (POST)
+func (s *Server) Presets(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.presets.list())
+	case http.MethodPost:
+		var req presetRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка декодирования JSON: %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.Name == "" || strings.Contains(req.Name, "/") {
+			http.Error(w, errPresetName.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.RLock()
+		cfg := *s.config
+		s.mu.RUnlock()
+		if err := s.presets.save(req.Name, &cfg); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.log("info", i18n.T("server.presetSaved", req.Name))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ApplyPreset делает пресет {name} текущей конфигурацией. Во время запуска запрещено:
+// работающий отправитель держит прежнюю конфигурацию, и подмена разошлась бы с ней
+func (s *Server) ApplyPreset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.PathValue("name")
+	preset, ok := s.presets.get(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("%v: %s", errPresetNotFound, name), http.StatusNotFound)
+		return
+	}
+	// Пресет проверяется заново: файлы, на которые он ссылается, могли измениться
+	if err := preset.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Файл пресетов мог быть записан при другом -pre-send-command
+	if err := s.checkPreSendCommand(preset); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	s.mu.Lock()
+	if s.senderCancel != nil {
+		s.mu.Unlock()
+		http.Error(w, "Отправка запущена, остановите её перед применением пресета", http.StatusConflict)
+		return
+	}
+	cfg := *preset
+	s.config = &cfg
+	s.configSource = ConfigSourcePreset
+	s.mu.Unlock()
+
+	sender.SetOverflowPolicy(cfg.LogOverflowPolicy)
+	s.applyLogLevels(&cfg)
+
+	s.log("info", i18n.T("server.presetApplied", name))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
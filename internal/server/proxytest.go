@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/sender"
+	"SendMsgTestForTG/internal/telegram"
+)
+
+// proxyTestRequest тело запроса /api/proxy/test; пустой proxyURL — прокси из текущей конфигурации
+type proxyTestRequest struct {
+	ProxyURL string `json:"proxyURL"`
+}
+
+// ProxyTestResult результат проверки прокси: этапы соединения в миллисекундах
+type ProxyTestResult struct {
+	OK        bool    `json:"ok"`
+	ProxyURL  string  `json:"proxyURL"`
+	DNSMs     float64 `json:"dnsMs"`
+	ConnectMs float64 `json:"connectMs"`
+	TLSMs     float64 `json:"tlsMs"`
+	TotalMs   float64 `json:"totalMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// TestProxy проверяет, что через прокси устанавливается туннель и TLS с Bot API, не отправляя сообщений
+func (s *Server) TestProxy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req proxyTestRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка декодирования JSON: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mu.RLock()
+	timeout := s.config.Timeout
+	dnsServer, dohEndpoint, localAddr := s.config.DNSServer, s.config.DoHEndpoint, s.config.LocalAddr
+	tlsDetails, tlsExpiryWarning := s.config.VerifyTLSDetails, s.config.TLSExpiryWarning
+	if req.ProxyURL == "" {
+		req.ProxyURL = s.config.ProxyURL
+	}
+	s.mu.RUnlock()
+
+	logFunc := func(level, message string, meta telegram.LogMeta) {
+		s.logEntry(sender.NewLogEntry(level, message, meta))
+	}
+	client, err := telegram.NewClient(timeout, req.ProxyURL, true, "", logFunc,
+		telegram.WithDNSServer(dnsServer), telegram.WithDoH(dohEndpoint), telegram.WithLocalAddr(localAddr),
+		telegram.WithTLSDetails(tlsDetails, tlsExpiryWarning))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Ошибка создания клиента: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	timings, err := client.CheckConnection(ctx)
+	result := ProxyTestResult{
+		OK:        err == nil,
+		ProxyURL:  req.ProxyURL,
+		DNSMs:     durationMs(timings.DNS),
+		ConnectMs: durationMs(timings.Connect),
+		TLSMs:     durationMs(timings.TLS),
+		TotalMs:   durationMs(timings.Total),
+	}
+	if err != nil {
+		result.Error = err.Error()
+		s.log("warn", i18n.T("server.proxyTestFailed", err))
+	} else {
+		s.log("info", i18n.T("server.proxyTestOK", timings.Total))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// durationMs переводит длительность в миллисекунды для ответов API
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
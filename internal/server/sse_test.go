@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"SendMsgTestForTG/internal/sender"
+)
+
+// subscriberCount число подключённых подписчиков
+func subscriberCount(s *Server) int {
+	s.subMu.RLock()
+	defer s.subMu.RUnlock()
+	return len(s.subscribers)
+}
+
+// waitFor ждёт выполнения cond
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("не дождались: %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSSEClientDisconnectMidStream(t *testing.T) {
+	s := NewServer()
+	srv := httptest.NewServer(http.HandlerFunc(s.LogsSSE))
+	defer srv.Close()
+	goroutines := runtime.NumGoroutine()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "подписка клиента", func() bool { return subscriberCount(s) == 1 })
+
+	sseSink{s}.RecordLog(sender.LogEntry{ID: 1, Level: "info", Message: "первая"})
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil || line == "" {
+		t.Fatalf("первое событие не получено: %q, %v", line, err)
+	}
+	// Клиент обрывает соединение посреди потока, записи продолжают поступать
+	resp.Body.Close()
+	for i := 2; i < 50; i++ {
+		sseSink{s}.RecordLog(sender.LogEntry{ID: int64(i), Level: "info", Message: "после обрыва"})
+	}
+
+	waitFor(t, "отписка после обрыва", func() bool { return subscriberCount(s) == 0 })
+	srv.CloseClientConnections()
+	waitFor(t, "завершение горутин подписчика", func() bool { return runtime.NumGoroutine() <= goroutines })
+}
+
+// brokenWriter ResponseWriter, запись в который после broken возвращает EPIPE, как оборванный сокет
+type brokenWriter struct {
+	header http.Header
+
+	mu     sync.Mutex
+	broken bool
+	writes int
+}
+
+func (w *brokenWriter) Header() http.Header { return w.header }
+
+func (w *brokenWriter) WriteHeader(int) {}
+
+func (w *brokenWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.broken {
+		return 0, syscall.EPIPE
+	}
+	w.writes++
+	return len(p), nil
+}
+
+func (w *brokenWriter) Flush() {}
+
+func (w *brokenWriter) breakPipe() {
+	w.mu.Lock()
+	w.broken = true
+	w.mu.Unlock()
+}
+
+func TestSSEWriteErrorEndsStream(t *testing.T) {
+	s := NewServer()
+	w := &brokenWriter{header: make(http.Header)}
+	// Контекст запроса не отменяется: поток должен завершиться по ошибке записи
+	req := httptest.NewRequest(http.MethodGet, "/api/logs", nil).WithContext(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.LogsSSE(w, req)
+	}()
+	waitFor(t, "подписка клиента", func() bool { return subscriberCount(s) == 1 })
+
+	w.breakPipe()
+	sseSink{s}.RecordLog(sender.LogEntry{ID: 1, Level: "info", Message: "в оборванное соединение"})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("обработчик не завершился после ошибки записи")
+	}
+	if n := subscriberCount(s); n != 0 {
+		t.Errorf("после ошибки записи осталось подписчиков: %d", n)
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/sender"
+)
+
+// defaultStatsInterval период снимков статистики в SSE, если StatsInterval не задан
+const defaultStatsInterval = time.Second
+
+// broadcastStats во время запуска каждые StatsInterval рассылает SSE-подписчикам полный снимок
+// статистики (событие type "stats") и темп за прошедший период. Снимки не попадают в историю
+// и файловые приёмники; без подписчиков не формируются
+func (s *Server) broadcastStats() {
+	var (
+		prevStats *sender.Stats
+		prevTotal int
+		prevAt    time.Time
+	)
+	for {
+		s.mu.RLock()
+		interval := s.config.StatsInterval
+		s.mu.RUnlock()
+		if interval <= 0 {
+			interval = defaultStatsInterval
+		}
+		time.Sleep(interval)
+
+		s.mu.RLock()
+		stats := s.stats
+		running := s.senderCancel != nil
+		s.mu.RUnlock()
+		s.subMu.RLock()
+		watched := s.watchers() > 0
+		s.subMu.RUnlock()
+		if !running || !watched {
+			continue
+		}
+
+		now := time.Now()
+		snap := stats.Snapshot()
+		// Темп считаем от предыдущего снимка того же запуска, для нового запуска — от его начала
+		if stats != prevStats {
+			prevStats, prevTotal, prevAt = stats, 0, snap.Started
+		}
+		var rate float64
+		if elapsed := now.Sub(prevAt).Seconds(); elapsed > 0 {
+			rate = float64(snap.Total-prevTotal) / elapsed
+		}
+		prevTotal, prevAt = snap.Total, now
+
+		s.logEntry(sender.LogEntry{
+			RunID:    snap.RunID,
+			Time:     now,
+			Type:     sender.EventStats,
+			Level:    "info",
+			Message:  i18n.T("server.statsEvent", snap.Total, snap.Success, snap.Errors, rate),
+			Category: sender.CategoryRun,
+			Fields:   map[string]interface{}{"stats": snap, "rps": rate},
+		})
+	}
+}
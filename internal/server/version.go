@@ -0,0 +1,27 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+)
+
+// BuildInfo сведения о сборке, задаются через -ldflags в main
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+}
+
+// SetBuildInfo задаёт сведения о сборке для /api/version
+func (s *Server) SetBuildInfo(info BuildInfo) {
+	info.GoVersion = runtime.Version()
+	s.buildInfo = info
+}
+
+// GetVersion возвращает сведения о запущенной сборке
+func (s *Server) GetVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.buildInfo)
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+	"SendMsgTestForTG/internal/sender"
+)
+
+// webhookTimeout ограничивает время отправки уведомления о завершении запуска
+const webhookTimeout = 10 * time.Second
+
+// RunSummary итоги запуска, отправляемые в CompletionWebhook
+type RunSummary struct {
+	RunID    string               `json:"runId"`
+	RunLabel string               `json:"runLabel,omitempty"`
+	Reason   string               `json:"reason"`
+	Started  time.Time            `json:"started"`
+	Finished time.Time            `json:"finished"`
+	Stats    sender.StatsSnapshot `json:"stats"`
+}
+
+// sendCompletionWebhook отправляет POST с итогами запуска. Ошибки только логируются
+func (s *Server) sendCompletionWebhook(webhookURL string, summary RunSummary) {
+	body, err := json.Marshal(summary)
+	if err != nil {
+		s.log("error", i18n.T("server.webhookError", err))
+		return
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		s.log("error", i18n.T("server.webhookError", err))
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		s.log("error", i18n.T("server.webhookError", fmt.Sprintf("status %d", resp.StatusCode)))
+		return
+	}
+	s.log("info", i18n.T("server.webhookSent", webhookURL, resp.StatusCode))
+}
@@ -0,0 +1,88 @@
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"SendMsgTestForTG/internal/sender"
+)
+
+// Sink приёмник результатов, отправляющий метрики в StatsD по UDP (-statsd). Метрики:
+// <prefix>.requests, .success, .errors, .errors.<класс> (счётчики), .duration и .phase.<этап>
+// (время, мс), .logs.warn и .logs.error (записи лога). Запросы прогрева не отправляются.
+// Отправка не ждёт ответа и ошибки игнорирует: потеря пакета не должна тормозить запуск
+type Sink struct {
+	conn   net.Conn
+	prefix string
+}
+
+// New создаёт приёмник для сервера addr (host:port); prefix — начало имён метрик
+func New(addr, prefix string) (*Sink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("statsd: %w", err)
+	}
+	return &Sink{conn: conn, prefix: strings.TrimSuffix(prefix, ".")}, nil
+}
+
+// RecordResult отправляет счётчики и длительности одного запроса одним пакетом
+func (s *Sink) RecordResult(e sender.RequestEvent) {
+	if e.Warmup {
+		return
+	}
+	var b strings.Builder
+	s.count(&b, "requests")
+	if e.Success {
+		s.count(&b, "success")
+	} else {
+		s.count(&b, "errors")
+		s.count(&b, "errors."+e.ErrorClass)
+	}
+	s.timing(&b, "duration", e.Duration)
+	phases := map[string]time.Duration{
+		sender.PhaseDNS:      e.Timings.DNS,
+		sender.PhaseConnect:  e.Timings.Connect,
+		sender.PhaseTLS:      e.Timings.TLS,
+		sender.PhaseTTFB:     e.Timings.TTFB,
+		sender.PhaseBodyRead: e.Timings.BodyRead,
+		sender.PhaseUpload:   e.Timings.Upload,
+	}
+	for phase, d := range phases {
+		if d > 0 {
+			s.timing(&b, "phase."+phase, d)
+		}
+	}
+	s.send(b.String())
+}
+
+// RecordLog считает предупреждения и ошибки в логе
+func (s *Sink) RecordLog(entry sender.LogEntry) {
+	if entry.Level != "warn" && entry.Level != "error" {
+		return
+	}
+	var b strings.Builder
+	s.count(&b, "logs."+entry.Level)
+	s.send(b.String())
+}
+
+// Close закрывает UDP-сокет
+func (s *Sink) Close() error {
+	return s.conn.Close()
+}
+
+// count добавляет в пакет увеличение счётчика на 1
+func (s *Sink) count(b *strings.Builder, name string) {
+	fmt.Fprintf(b, "%s.%s:1|c\n", s.prefix, name)
+}
+
+// timing добавляет в пакет длительность в миллисекундах
+func (s *Sink) timing(b *strings.Builder, name string, d time.Duration) {
+	fmt.Fprintf(b, "%s.%s:%.3f|ms\n", s.prefix, name, float64(d)/float64(time.Millisecond))
+}
+
+// send отправляет пакет без завершающего перевода строки
+func (s *Sink) send(packet string) {
+	s.conn.Write([]byte(strings.TrimSuffix(packet, "\n")))
+}
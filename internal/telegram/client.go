@@ -1,31 +1,296 @@
 package telegram
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net"
 	"net/http"
 	"net/http/httptrace"
+	"net/http/httputil"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"SendMsgTestForTG/internal/i18n"
 )
 
 // LogFunc тип функции для логирования
-type LogFunc func(level, message string)
+type LogFunc func(level, message string, meta LogMeta)
+
+// LogMeta содержит структурированные поля записи лога
+type LogMeta struct {
+	RequestNum int
+	Category   string
+	Fields     map[string]interface{}
+}
+
+// Категории записей лога клиента
+const (
+	CategoryClient = "client"
+	CategoryDial   = "dial"
+	CategoryProxy  = "proxy"
+	CategoryConn   = "conn"
+	CategoryDNS    = "dns"
+	CategoryTCP    = "tcp"
+	CategoryTLS    = "tls"
+	CategoryHTTP   = "http"
+)
+
+type requestNumKey struct{}
+
+// WithRequestNum сохраняет номер запроса в контексте для структурированных логов
+func WithRequestNum(ctx context.Context, requestNum int) context.Context {
+	return context.WithValue(ctx, requestNumKey{}, requestNum)
+}
+
+// RequestNumFromContext возвращает номер запроса из контекста (0, если не задан)
+func RequestNumFromContext(ctx context.Context) int {
+	n, _ := ctx.Value(requestNumKey{}).(int)
+	return n
+}
+
+type quietKey struct{}
+
+type discardBodyKey struct{}
+
+// WithQuiet помечает запрос как «тихий»: клиент не пишет по нему info-записи трейсинга,
+// предупреждения и ошибки остаются
+func WithQuiet(ctx context.Context) context.Context {
+	return context.WithValue(ctx, quietKey{}, true)
+}
+
+// logCtx пишет запись с номером запроса из контекста
+func logCtx(logFunc LogFunc, ctx context.Context, level, category, message string, fields map[string]interface{}) {
+	if quiet, _ := ctx.Value(quietKey{}).(bool); quiet && level == "info" {
+		return
+	}
+	logFunc(level, message, LogMeta{
+		RequestNum: RequestNumFromContext(ctx),
+		Category:   category,
+		Fields:     fields,
+	})
+}
+
+// durationMs переводит длительность в миллисекунды для полей лога
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// Кодировки тела запроса к Bot API
+const (
+	EncodingForm      = "form"
+	EncodingJSON      = "json"
+	EncodingMultipart = "multipart"
+)
+
+// DefaultBaseURL адрес Telegram Bot API по умолчанию
+const DefaultBaseURL = "https://api.telegram.org"
 
 // Client представляет клиент для работы с Telegram Bot API
 type Client struct {
 	httpClient *http.Client
 	logFunc    LogFunc
 	proxyURL   string
+	encoding   string
+	baseURL    string
+	dumpReqs   bool
+	// signingHeader и signingSecret для HMAC-подписи тела запроса; секрет не логируется
+	signingHeader string
+	signingSecret string
+	dnsServer     string
+	dohEndpoint   string
+	localAddr     string
+	// maxBody предел размера тела ответа, которое читается в память
+	maxBody int64
+	// tlsDetails логировать цепочку сертификатов, tlsExpiryWarning — окно предупреждения об истечении
+	tlsDetails       bool
+	tlsExpiryWarning time.Duration
+	// maxReqPerConn каждый такой по счёту запрос закрывает соединение; requests — счётчик запросов
+	maxReqPerConn int
+	requests      atomic.Int64
+	// traceSummary заменяет info-записи трейсинга запроса одной строкой с итогами этапов
+	traceSummary bool
+	// discardBody успешный ответ sendMessage не читается в память, а вычитывается со счётом байт
+	discardBody bool
+	// proxyPool адреса пула прокси и число запросов подряд через один прокси; pool — селектор после создания
+	proxyPool       []string
+	proxyStickiness int
+	pool            *proxyPool
+}
+
+// Option настраивает клиент при создании
+type Option func(*Client)
+
+// WithHTTPClient подменяет HTTP клиент целиком (например, для тестов)
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport подменяет транспорт HTTP клиента, сохраняя его таймаут
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = transport
+	}
+}
+
+// WithBaseURL задаёт адрес Bot API вместо api.telegram.org (например, httptest.Server)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithRequestDump включает запись в лог полного исходящего запроса (строка запроса,
+// заголовки и тело) с замаскированным токеном
+func WithRequestDump(enabled bool) Option {
+	return func(c *Client) {
+		c.dumpReqs = enabled
+	}
+}
+
+// WithRequestSigning добавляет к каждому запросу с готовым телом заголовок header
+// с HMAC-SHA256 тела (hex) под ключом secret — для шлюзов перед Bot API
+func WithRequestSigning(header, secret string) Option {
+	return func(c *Client) {
+		c.signingHeader = header
+		c.signingSecret = secret
+	}
+}
+
+// WithLocalAddr привязывает исходящие соединения к локальному адресу: IP или IP:port.
+// С фиксированным портом одновременно может быть открыто только одно соединение
+func WithLocalAddr(addr string) Option {
+	return func(c *Client) {
+		c.localAddr = addr
+	}
+}
+
+// WithMaxResponseBody ограничивает размер тела ответа, читаемого в память (0 — DefaultMaxResponseBody).
+// Ответ больше предела не дочитывается и возвращается ErrResponseTooLarge
+func WithMaxResponseBody(n int64) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxBody = n
+		}
+	}
+}
+
+// WithMaxRequestsPerConn каждый n-й запрос отправляет с Connection: close, чтобы следующий открыл
+// новое соединение (0 — без ограничения). Счётчик общий для клиента: при последовательной отправке
+// это ровно n запросов на соединение, при параллельной — n запросов на все соединения
+func WithMaxRequestsPerConn(n int) Option {
+	return func(c *Client) {
+		c.maxReqPerConn = n
+	}
+}
+
+// WithDiscardResponseBody тело ответа 200 на sendMessage вычитывается через io.Discard с подсчётом
+// байт и не разбирается: для замеров задержки и пропускной способности, где содержимое ответа
+// не нужно. ID и дата сообщения при этом неизвестны; ответы с ошибкой читаются полностью
+func WithDiscardResponseBody(enabled bool) Option {
+	return func(c *Client) {
+		c.discardBody = enabled
+	}
+}
+
+// WithProxyPool отправляет запросы через пул прокси вместо proxyURL: один прокси обслуживает
+// stickiness запросов подряд, затем случайно выбирается другой. Пустая строка в пуле — прямое
+// подключение; пустой пул или stickiness <= 0 — пул не используется
+func WithProxyPool(proxies []string, stickiness int) Option {
+	return func(c *Client) {
+		c.proxyPool = proxies
+		c.proxyStickiness = stickiness
+	}
+}
+
+// WithTraceSummary вместо отдельных info-записей по этапам (соединение, DNS, TCP, TLS, ответ)
+// пишет по каждому HTTP-запросу одну строку с длительностями этапов и статусом.
+// Предупреждения и ошибки трейсинга остаются
+func WithTraceSummary(enabled bool) Option {
+	return func(c *Client) {
+		c.traceSummary = enabled
+	}
+}
+
+// DefaultMaxResponseBody предел тела ответа по умолчанию; ответы Bot API на порядки меньше
+const DefaultMaxResponseBody = 10 << 20
+
+// ErrResponseTooLarge ответ превысил допустимый размер тела
+var ErrResponseTooLarge = errors.New("ответ превысил максимальный размер")
+
+// readLimited читает тело не больше limit байт; при превышении возвращает ErrResponseTooLarge,
+// не выделяя память под остаток
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w (%d байт)", ErrResponseTooLarge, limit)
+	}
+	return body, nil
+}
+
+// signBody вычисляет hex-кодированный HMAC-SHA256 тела запроса
+func signBody(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseLocalAddr разбирает локальный адрес для исходящих соединений: IP или IP:port
+func ParseLocalAddr(addr string) (*net.TCPAddr, error) {
+	if ip := net.ParseIP(addr); ip != nil {
+		return &net.TCPAddr{IP: ip}, nil
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("локальный адрес %q: %w", addr, err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("локальный адрес %q: нужен IP, а не имя хоста", addr)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("локальный адрес %q: некорректный порт", addr)
+	}
+	return &net.TCPAddr{IP: ip, Port: int(p)}, nil
+}
+
+// maxDumpBody тело запроса больше этого размера (например, загрузка файла) в дамп не попадает
+const maxDumpBody = 64 << 10
+
+// proxyConnectLogger callback транспорта для логирования ответа прокси на CONNECT
+func proxyConnectLogger(logFunc LogFunc) func(context.Context, *url.URL, *http.Request, *http.Response) error {
+	return func(ctx context.Context, proxyURL *url.URL, connectReq *http.Request, connectRes *http.Response) error {
+		logCtx(logFunc, ctx, "info", CategoryProxy, i18n.T("client.proxyConnect",
+			proxyURL.Host, connectRes.StatusCode, connectRes.Status), map[string]interface{}{"status": connectRes.StatusCode})
+		if connectRes.StatusCode != 200 {
+			logCtx(logFunc, ctx, "error", CategoryProxy, i18n.T("client.proxyConnectFailed", connectRes.StatusCode),
+				map[string]interface{}{"status": connectRes.StatusCode})
+		}
+		return nil
+	}
 }
 
 // NewClient создает новый клиент Telegram
-func NewClient(timeout time.Duration, proxyURL string, disableKeepAlive bool, logFunc LogFunc) (*Client, error) {
+func NewClient(timeout time.Duration, proxyURL string, disableKeepAlive bool, encoding string, logFunc LogFunc, opts ...Option) (*Client, error) {
 	// Создаём кастомный dialer с логированием
 	baseDialer := &net.Dialer{
 		Timeout:   30 * time.Second,
@@ -34,19 +299,21 @@ func NewClient(timeout time.Duration, proxyURL string, disableKeepAlive bool, lo
 
 	// Оборачиваем dialer для логирования
 	dialContext := func(ctx context.Context, network, addr string) (net.Conn, error) {
-		logFunc("info", fmt.Sprintf("🔌 Dialer: начало подключения к %s (%s)", addr, network))
+		logCtx(logFunc, ctx, "info", CategoryDial, i18n.T("client.dialStart", addr, network), map[string]interface{}{"addr": addr})
 		dialStart := time.Now()
 
 		conn, err := baseDialer.DialContext(ctx, network, addr)
 		dialDuration := time.Since(dialStart)
 
 		if err != nil {
-			logFunc("error", fmt.Sprintf("🔌 Dialer: ошибка подключения к %s за %v: %v", addr, dialDuration, err))
+			logCtx(logFunc, ctx, "error", CategoryDial, i18n.T("client.dialError", addr, dialDuration, err),
+				map[string]interface{}{"addr": addr, "durationMs": durationMs(dialDuration)})
 			return nil, err
 		}
 
-		logFunc("info", fmt.Sprintf("🔌 Dialer: подключено к %s за %v (local: %s)", addr, dialDuration, conn.LocalAddr()))
-		return conn, nil
+		logCtx(logFunc, ctx, "info", CategoryDial, i18n.T("client.dialDone", addr, dialDuration, conn.LocalAddr()),
+			map[string]interface{}{"addr": addr, "durationMs": durationMs(dialDuration), "localAddr": conn.LocalAddr().String()})
+		return newTrackedConn(conn, logFunc, RequestNumFromContext(ctx)), nil
 	}
 
 	transport := &http.Transport{
@@ -60,7 +327,7 @@ func NewClient(timeout time.Duration, proxyURL string, disableKeepAlive bool, lo
 	}
 
 	if disableKeepAlive {
-		logFunc("info", "🔄 Keep-Alive отключён: каждый запрос будет использовать новое соединение")
+		logFunc("info", i18n.T("client.keepAliveDisabled"), LogMeta{Category: CategoryClient})
 	}
 
 	if proxyURL != "" {
@@ -68,231 +335,943 @@ func NewClient(timeout time.Duration, proxyURL string, disableKeepAlive bool, lo
 		if err != nil {
 			return nil, fmt.Errorf("ошибка парсинга прокси URL: %w", err)
 		}
+		if parsedProxyURL.Scheme == "" || parsedProxyURL.Host == "" {
+			return nil, fmt.Errorf("прокси URL %q должен содержать схему и хост", proxyURL)
+		}
 		transport.Proxy = http.ProxyURL(parsedProxyURL)
 
-		// Добавляем callback для логирования CONNECT запроса к прокси
-		transport.OnProxyConnectResponse = func(ctx context.Context, proxyURL *url.URL, connectReq *http.Request, connectRes *http.Response) error {
-			logFunc("info", fmt.Sprintf("🔀 Proxy CONNECT: ответ от прокси %s -> статус %d %s",
-				proxyURL.Host, connectRes.StatusCode, connectRes.Status))
-			if connectRes.StatusCode != 200 {
-				logFunc("error", fmt.Sprintf("🔀 Proxy CONNECT: туннель не установлен, код %d", connectRes.StatusCode))
-			}
-			return nil
-		}
+		transport.OnProxyConnectResponse = proxyConnectLogger(logFunc)
 
-		logFunc("info", fmt.Sprintf("🔀 Прокси настроен: %s (схема: %s, хост: %s)", proxyURL, parsedProxyURL.Scheme, parsedProxyURL.Host))
+		logFunc("info", i18n.T("client.proxyConfigured", proxyURL, parsedProxyURL.Scheme, parsedProxyURL.Host), LogMeta{Category: CategoryProxy})
 		if parsedProxyURL.User != nil {
-			logFunc("info", fmt.Sprintf("🔀 Прокси аутентификация: пользователь '%s'", parsedProxyURL.User.Username()))
+			logFunc("info", i18n.T("client.proxyAuth", parsedProxyURL.User.Username()), LogMeta{Category: CategoryProxy})
 		}
 	} else {
-		logFunc("info", "Прокси не используется (прямое подключение)")
+		logFunc("info", i18n.T("client.proxyNone"), LogMeta{Category: CategoryProxy})
+	}
+
+	if encoding == "" {
+		encoding = EncodingForm
 	}
+	logFunc("info", i18n.T("client.encoding", encoding), LogMeta{Category: CategoryClient})
 
-	logFunc("info", fmt.Sprintf("HTTP клиент создан. Timeout: %v, DialTimeout: 30s, TLSHandshake: 15s, ResponseHeader: 30s", timeout))
+	logFunc("info", i18n.T("client.created", timeout), LogMeta{Category: CategoryClient})
 
-	return &Client{
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout:   timeout,
 			Transport: transport,
 		},
 		logFunc:  logFunc,
 		proxyURL: proxyURL,
-	}, nil
+		encoding: encoding,
+		baseURL:  DefaultBaseURL,
+		maxBody:  DefaultMaxResponseBody,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.localAddr != "" {
+		addr, err := ParseLocalAddr(c.localAddr)
+		if err != nil {
+			return nil, err
+		}
+		baseDialer.LocalAddr = addr
+		logFunc("info", i18n.T("client.localAddr", addr), LogMeta{Category: CategoryDial})
+	}
+
+	if len(c.proxyPool) > 0 && c.proxyStickiness > 0 {
+		pool, err := newProxyPool(c.proxyPool, c.proxyStickiness, logFunc)
+		if err != nil {
+			return nil, err
+		}
+		c.pool = pool
+		transport.Proxy = pool.proxy
+		transport.OnProxyConnectResponse = proxyConnectLogger(logFunc)
+		logFunc("info", i18n.T("client.proxyPool", len(pool.proxies), c.proxyStickiness), LogMeta{
+			Category: CategoryProxy,
+			Fields:   map[string]interface{}{"proxies": len(pool.proxies), "stickiness": c.proxyStickiness},
+		})
+	}
+
+	// Резолвер зависит от опций, поэтому подключается к дайлеру после их применения
+	baseDialer.Resolver = c.newResolver()
+	switch {
+	case c.dohEndpoint != "":
+		logFunc("info", i18n.T("client.resolverDoH", c.dohEndpoint), LogMeta{Category: CategoryDNS})
+	case c.dnsServer != "":
+		logFunc("info", i18n.T("client.resolverServer", c.dnsServer), LogMeta{Category: CategoryDNS})
+	default:
+		logFunc("info", i18n.T("client.resolverSystem"), LogMeta{Category: CategoryDNS})
+	}
+	if (proxyURL != "" || c.pool != nil) && baseDialer.Resolver != nil {
+		logFunc("info", i18n.T("client.resolverProxyNote"), LogMeta{Category: CategoryDNS})
+	}
+
+	return c, nil
 }
 
-// SendMessage отправляет сообщение в Telegram
-func (c *Client) SendMessage(ctx context.Context, chatID, botToken, messageThreadID, message string) error {
+// SendResult содержит результат отправки сообщения
+type SendResult struct {
+	MessageID int64
+	// Date время отправки, назначенное Telegram (поле date сообщения, точность — секунда);
+	// нулевое, если ответ без сообщения
+	Date    time.Time
+	Timings Timings
+}
+
+// Timings содержит длительность этапов запроса. Нулевое значение этапа означает,
+// что он не выполнялся (например, DNS/TCP/TLS при переиспользовании соединения)
+type Timings struct {
+	DNS        time.Duration
+	Connect    time.Duration
+	TLS        time.Duration
+	TTFB       time.Duration
+	BodyRead   time.Duration
+	Upload     time.Duration
+	Total      time.Duration
+	ConnReused bool
+	// NewConn для попытки открыто новое соединение (GotConn без Reused); без соединения
+	// (ошибка до GotConn) ложны и NewConn, и ConnReused
+	NewConn bool
+	// BytesSent и BytesReceived размеры тел запроса и ответа; запрос, который не дошёл
+	// до записи в соединение, не учитывается
+	BytesSent     int64
+	BytesReceived int64
+	// HeaderBytesSent и HeaderBytesReceived приблизительный объём стартовой строки и заголовков
+	HeaderBytesSent     int64
+	HeaderBytesReceived int64
+}
+
+// apiResponse представляет ответ Telegram Bot API
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Parameters  *responseParams `json:"parameters"`
+}
+
+// responseParams поле parameters ответа с ошибкой (ResponseParameters)
+type responseParams struct {
+	MigrateToChatID int64 `json:"migrate_to_chat_id"`
+	RetryAfter      int   `json:"retry_after"`
+}
+
+// APIError ошибка, которую вернул Bot API (ok=false), с разобранными parameters
+type APIError struct {
+	StatusCode  int
+	ErrorCode   int
+	Description string
+	// MigrateToChatID новый ID чата, если группа преобразована в супергруппу
+	MigrateToChatID int64
+	// RetryAfter через сколько секунд можно повторить запрос (ошибка 429)
+	RetryAfter int
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram API: status %d, %d %s", e.StatusCode, e.ErrorCode, e.Description)
+}
+
+// IsParseError сообщает, что Telegram не смог разобрать разметку текста (400 "can't parse entities")
+func (e *APIError) IsParseError() bool {
+	return e.ErrorCode == http.StatusBadRequest && strings.Contains(strings.ToLower(e.Description), "can't parse entities")
+}
+
+// IsBusinessConnectionError сообщает, что Telegram отклонил business_connection_id:
+// подключение не найдено, отключено или бот не подключён к бизнес-аккаунту
+func (e *APIError) IsBusinessConnectionError() bool {
+	return e.ErrorCode == http.StatusBadRequest && strings.Contains(strings.ToLower(e.Description), "business")
+}
+
+// IsThreadError сообщает, что Telegram отклонил message_thread_id: тред не найден или закрыт
+func (e *APIError) IsThreadError() bool {
+	description := strings.ToLower(e.Description)
+	return e.ErrorCode == http.StatusBadRequest && (strings.Contains(description, "thread") || strings.Contains(description, "topic"))
+}
+
+// IsReplyError сообщает, что не найдено сообщение для ответа (reply_to_message_id)
+func (e *APIError) IsReplyError() bool {
+	return e.ErrorCode == http.StatusBadRequest && strings.Contains(strings.ToLower(e.Description), "repl")
+}
+
+// newAPIError собирает APIError из разобранного ответа
+func newAPIError(statusCode int, resp *apiResponse) *APIError {
+	apiErr := &APIError{
+		StatusCode:  statusCode,
+		ErrorCode:   resp.ErrorCode,
+		Description: resp.Description,
+	}
+	if resp.Parameters != nil {
+		apiErr.MigrateToChatID = resp.Parameters.MigrateToChatID
+		apiErr.RetryAfter = resp.Parameters.RetryAfter
+	}
+	return apiErr
+}
+
+// sentMessage содержит нужные нам поля объекта Message из ответа
+type sentMessage struct {
+	MessageID int64 `json:"message_id"`
+	// Date Unix-время отправки по часам Telegram
+	Date int64 `json:"date"`
+}
+
+// date время отправки сообщения; нулевое, если поля date нет
+func (m sentMessage) date() time.Time {
+	if m.Date == 0 {
+		return time.Time{}
+	}
+	return time.Unix(m.Date, 0)
+}
+
+// MessageOptions необязательные параметры sendMessage
+type MessageOptions struct {
+	// Entities JSON-массив MessageEntity; если задан, parse_mode не передаётся
+	Entities json.RawMessage
+	// DisableNotification доставляет сообщение без звука (disable_notification)
+	DisableNotification bool
+	// ProtectContent запрещает пересылку и сохранение сообщения (protect_content)
+	ProtectContent bool
+	// ReplyToMessageID ответ на сообщение; внутри треда оно должно быть из того же треда
+	ReplyToMessageID string
+	// AllowSendingWithoutReply отправить, даже если сообщение для ответа не найдено
+	AllowSendingWithoutReply bool
+	// LinkPreviewOptions JSON-объект link_preview_options; если задан, устаревший
+	// disable_web_page_preview не передаётся
+	LinkPreviewOptions json.RawMessage
+	// PlainText отправить текст без разметки: ни parse_mode, ни entities не передаются
+	PlainText bool
+	// BusinessConnectionID отправка от имени бизнес-аккаунта (business_connection_id)
+	BusinessConnectionID string
+	// MessageEffectID эффект сообщения (message_effect_id); работает только в личных чатах
+	MessageEffectID string
+	// AllowPaidBroadcast платная рассылка сверх лимита скорости (allow_paid_broadcast)
+	AllowPaidBroadcast bool
+	// ExtraParams параметры, которые передаются как есть после известных; поля, уже
+	// заданные клиентом, не перекрываются. Позволяют проверить новые параметры Bot API
+	ExtraParams map[string]string
+}
+
+// SendMessage отправляет сообщение в Telegram. Результат возвращается и при ошибке:
+// в нём остаются замеры выполненных этапов
+func (c *Client) SendMessage(ctx context.Context, chatID, botToken, messageThreadID, message string, opts MessageOptions) (*SendResult, error) {
 	data := url.Values{}
 	data.Add("chat_id", chatID)
 	data.Add("text", message)
 	if messageThreadID != "" {
 		data.Add("message_thread_id", messageThreadID)
 	}
-	data.Add("parse_mode", "MarkdownV2")
-	data.Add("disable_web_page_preview", "True")
+	addFormatting(data, opts)
+	if opts.DisableNotification {
+		data.Add("disable_notification", "True")
+	}
+	if opts.ProtectContent {
+		data.Add("protect_content", "True")
+	}
+	if opts.ReplyToMessageID != "" {
+		data.Add("reply_to_message_id", opts.ReplyToMessageID)
+	}
+	if opts.AllowSendingWithoutReply {
+		data.Add("allow_sending_without_reply", "True")
+	}
+	if opts.BusinessConnectionID != "" {
+		data.Add("business_connection_id", opts.BusinessConnectionID)
+	}
+	if opts.MessageEffectID != "" {
+		data.Add("message_effect_id", opts.MessageEffectID)
+	}
+	if opts.AllowPaidBroadcast {
+		data.Add("allow_paid_broadcast", "True")
+	}
+	for name, value := range opts.ExtraParams {
+		if data.Has(name) {
+			c.log(ctx, "warn", CategoryHTTP, i18n.T("client.extraParamSkipped", name), map[string]interface{}{"param": name})
+			continue
+		}
+		data.Set(name, value)
+	}
+
+	result := &SendResult{}
+	if c.discardBody {
+		ctx = context.WithValue(ctx, discardBodyKey{}, true)
+	}
+	apiResp, err := c.call(ctx, botToken, "sendMessage", data, &result.Timings)
+	if err != nil || c.discardBody {
+		return result, err
+	}
+
+	var msg sentMessage
+	if err := json.Unmarshal(apiResp.Result, &msg); err != nil {
+		c.log(ctx, "warn", CategoryHTTP, i18n.T("client.messageIDParseError", err), nil)
+	} else {
+		c.log(ctx, "info", CategoryHTTP, i18n.T("client.messageSent", msg.MessageID),
+			map[string]interface{}{"messageID": msg.MessageID})
+	}
+
+	result.MessageID = msg.MessageID
+	result.Date = msg.date()
+	return result, nil
+}
+
+// EditMessageText заменяет текст отправленного сообщения. Из opts используются только
+// разметка, превью ссылок и бизнес-подключение; Entities не передаются — их смещения относятся к исходному тексту
+func (c *Client) EditMessageText(ctx context.Context, chatID, botToken string, messageID int64, text string, opts MessageOptions) (*SendResult, error) {
+	data := url.Values{}
+	data.Add("chat_id", chatID)
+	data.Add("message_id", strconv.FormatInt(messageID, 10))
+	data.Add("text", text)
+	opts.Entities = nil
+	addFormatting(data, opts)
+	if opts.BusinessConnectionID != "" {
+		data.Add("business_connection_id", opts.BusinessConnectionID)
+	}
+
+	result := &SendResult{MessageID: messageID}
+	if _, err := c.call(ctx, botToken, "editMessageText", data, &result.Timings); err != nil {
+		return result, err
+	}
+	c.log(ctx, "info", CategoryHTTP, i18n.T("client.messageEdited", messageID),
+		map[string]interface{}{"messageID": messageID})
+	return result, nil
+}
+
+// Markdown сообщает, что текст разбирается как MarkdownV2: не заданы ни PlainText, ни Entities
+func (o MessageOptions) Markdown() bool {
+	return !o.PlainText && !hasJSON(o.Entities)
+}
+
+// addFormatting добавляет параметры разметки и превью ссылок
+func addFormatting(data url.Values, opts MessageOptions) {
+	switch {
+	case opts.PlainText:
+	case hasJSON(opts.Entities):
+		data.Add("entities", string(opts.Entities))
+	default:
+		data.Add("parse_mode", "MarkdownV2")
+	}
+	if hasJSON(opts.LinkPreviewOptions) {
+		data.Add("link_preview_options", string(opts.LinkPreviewOptions))
+	} else {
+		data.Add("disable_web_page_preview", "True")
+	}
+}
+
+// hasJSON сообщает, задано ли JSON-значение: конфигурация из веб-интерфейса приходит с null
+func hasJSON(raw json.RawMessage) bool {
+	return len(raw) > 0 && string(raw) != "null"
+}
+
+// DeleteMessage удаляет ранее отправленное сообщение
+func (c *Client) DeleteMessage(ctx context.Context, chatID, botToken string, messageID int64) error {
+	data := url.Values{}
+	data.Add("chat_id", chatID)
+	data.Add("message_id", strconv.FormatInt(messageID, 10))
+
+	_, err := c.call(ctx, botToken, "deleteMessage", data, nil)
+	return err
+}
+
+// SendDocument загружает локальный файл методом sendDocument. Тело multipart/form-data
+// собирается потоково: файл читается с диска по мере отправки, а не целиком в память
+func (c *Client) SendDocument(ctx context.Context, chatID, botToken, messageThreadID, filePath, caption string) (*SendResult, error) {
+	result := &SendResult{}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		c.log(ctx, "error", CategoryHTTP, i18n.T("client.uploadOpenError", filePath, err), nil)
+		return result, fmt.Errorf("открытие файла: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return result, fmt.Errorf("чтение размера файла: %w", err)
+	}
+
+	// Заголовки полей и части с файлом пишем в буфер, а закрывающую границу — отдельно,
+	// чтобы заранее знать Content-Length без чтения файла
+	var head bytes.Buffer
+	w := multipart.NewWriter(&head)
+	fields := map[string]string{"chat_id": chatID}
+	if messageThreadID != "" {
+		fields["message_thread_id"] = messageThreadID
+	}
+	if caption != "" {
+		fields["caption"] = caption
+	}
+	for key, value := range fields {
+		if err := w.WriteField(key, value); err != nil {
+			return result, fmt.Errorf("кодирование multipart: %w", err)
+		}
+	}
+	if _, err := w.CreateFormFile("document", filepath.Base(filePath)); err != nil {
+		return result, fmt.Errorf("кодирование multipart: %w", err)
+	}
+	headLen := head.Len()
+	if err := w.Close(); err != nil {
+		return result, fmt.Errorf("кодирование multipart: %w", err)
+	}
+	tail := append([]byte(nil), head.Bytes()[headLen:]...)
+	head.Truncate(headLen)
+
+	contentLength := int64(head.Len()) + info.Size() + int64(len(tail))
+	c.log(ctx, "info", CategoryHTTP, i18n.T("client.uploadPrepared", filepath.Base(filePath), info.Size(), contentLength),
+		map[string]interface{}{"method": "sendDocument", "fileBytes": info.Size(), "bytes": contentLength})
+
+	body := io.MultiReader(&head, file, bytes.NewReader(tail))
+	// Тело передаётся потоково, поэтому подпись для загрузки не вычисляется; config.Validate не
+	// допускает uploadFile вместе с signingSecret
+	apiResp, err := c.do(ctx, botToken, "sendDocument", body, contentLength, w.FormDataContentType(), &result.Timings, "")
+	if err != nil {
+		return result, err
+	}
+
+	c.log(ctx, "info", CategoryHTTP, i18n.T("client.uploadDone", result.Timings.BytesSent, result.Timings.Upload),
+		map[string]interface{}{"bytes": result.Timings.BytesSent, "uploadMs": durationMs(result.Timings.Upload)})
+
+	var msg sentMessage
+	if err := json.Unmarshal(apiResp.Result, &msg); err == nil {
+		result.MessageID = msg.MessageID
+		result.Date = msg.date()
+	}
+	return result, nil
+}
+
+// GetMe вызывает метод getMe; используется как лёгкий запрос для проверки соединения
+func (c *Client) GetMe(ctx context.Context, botToken string) error {
+	_, err := c.call(ctx, botToken, "getMe", url.Values{}, nil)
+	return err
+}
+
+// CheckConnection устанавливает новое соединение с Bot API (через прокси клиента, если задан)
+// до завершения TLS handshake и возвращает замеры этапов. Сообщения не отправляются:
+// выполняется HEAD-запрос к корню API, код ответа не важен
+func (c *Client) CheckConnection(ctx context.Context) (*Timings, error) {
+	timings := &Timings{}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.baseURL+"/", nil)
+	if err != nil {
+		return timings, fmt.Errorf("создание запроса: %w", err)
+	}
+	// Закрываем соединение после проверки, чтобы каждая проверка мерила новое
+	req.Close = true
+
+	var dnsStart, connectStart, tlsStart time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:           func(httptrace.DNSDoneInfo) { timings.DNS = time.Since(dnsStart) },
+		ConnectStart:      func(string, string) { connectStart = time.Now() },
+		ConnectDone:       func(string, string, error) { timings.Connect = time.Since(connectStart) },
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			timings.TLS = time.Since(tlsStart)
+			if c.tlsDetails && err == nil {
+				c.logCertificates(ctx, state)
+			}
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(ctx, trace))
 
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", botToken)
-	c.logFunc("info", fmt.Sprintf("Подготовка запроса к %s", "api.telegram.org"))
+	c.log(ctx, "info", CategoryConn, i18n.T("client.checkStart", c.baseURL), nil)
+	transport := c.httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	start := time.Now()
+	// RoundTrip напрямую: редиректы не нужны, важен только факт установки соединения
+	resp, err := transport.RoundTrip(req)
+	timings.Total = time.Since(start)
+	if err != nil {
+		c.log(ctx, "error", CategoryConn, i18n.T("client.checkError", timings.Total, err),
+			map[string]interface{}{"durationMs": durationMs(timings.Total)})
+		return timings, fmt.Errorf("проверка соединения: %w", err)
+	}
+	resp.Body.Close()
+
+	c.log(ctx, "info", CategoryConn, i18n.T("client.checkDone", timings.Total, timings.DNS, timings.Connect, timings.TLS),
+		map[string]interface{}{
+			"durationMs": durationMs(timings.Total),
+			"dnsMs":      durationMs(timings.DNS),
+			"connectMs":  durationMs(timings.Connect),
+			"tlsMs":      durationMs(timings.TLS),
+		})
+	return timings, nil
+}
+
+// dumpRequest логирует запрос в том виде, в каком он уйдёт в сеть, маскируя токен бота.
+// DumpRequestOut читает тело и подменяет его копией, поэтому большие тела не выводятся
+func (c *Client) dumpRequest(ctx context.Context, req *http.Request, botToken string) {
+	withBody := req.ContentLength >= 0 && req.ContentLength <= maxDumpBody
+	dump, err := httputil.DumpRequestOut(req, withBody)
+	if err != nil {
+		c.log(ctx, "warn", CategoryHTTP, i18n.T("client.dumpError", err), nil)
+		return
+	}
 
+	text := string(dump)
+	if botToken != "" {
+		text = strings.ReplaceAll(text, botToken, redactedToken)
+	}
+	if !withBody {
+		text += i18n.T("client.dumpBodyOmitted", req.ContentLength)
+	}
+	c.log(ctx, "info", CategoryHTTP, i18n.T("client.requestDump", text), nil)
+}
+
+// redactedToken подставляется вместо токена бота в дампах запросов
+const redactedToken = "<TOKEN>"
+
+// call кодирует параметры в выбранную кодировку и выполняет запрос к методу Bot API.
+// Если timings не nil, в него записываются замеры этапов
+func (c *Client) call(ctx context.Context, botToken, method string, data url.Values, timings *Timings) (*apiResponse, error) {
+	c.log(ctx, "info", CategoryHTTP, i18n.T("client.prepareRequest", method, c.baseURL),
+		map[string]interface{}{"method": method})
+
+	reqBody, contentType, err := encodeParams(c.encoding, data)
+	if err != nil {
+		c.log(ctx, "error", CategoryHTTP, i18n.T("client.encodeError", c.encoding, err), nil)
+		return nil, fmt.Errorf("кодирование параметров: %w", err)
+	}
+
+	signature := ""
+	if c.signingHeader != "" {
+		signature = signBody(reqBody, c.signingSecret)
+	}
+	return c.do(ctx, botToken, method, bytes.NewReader(reqBody), int64(len(reqBody)), contentType, timings, signature)
+}
+
+// do выполняет запрос к методу Bot API с готовым телом, детальным трейсингом и разбором ответа.
+// Непустая signature передаётся в заголовке подписи (WithRequestSigning)
+func (c *Client) do(ctx context.Context, botToken, method string, reqBody io.Reader, contentLength int64, contentType string, timings *Timings, signature string) (*apiResponse, error) {
+	if timings == nil {
+		timings = &Timings{}
+	}
+
+	apiURL := fmt.Sprintf("%s/bot%s/%s", c.baseURL, botToken, method)
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
 		apiURL,
-		strings.NewReader(data.Encode()),
+		reqBody,
 	)
 	if err != nil {
-		c.logFunc("error", fmt.Sprintf("Ошибка создания запроса: %v", err))
-		return fmt.Errorf("создание запроса: %w", err)
+		c.log(ctx, "error", CategoryHTTP, i18n.T("client.createRequestError", err), nil)
+		return nil, fmt.Errorf("создание запроса: %w", err)
 	}
+	req.ContentLength = contentLength
+	timings.BytesSent = contentLength
 
 	req.Header.Set("Accept-Encoding", "gzip, deflate")
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Content-Type", contentType)
+	if signature != "" {
+		req.Header.Set(c.signingHeader, signature)
+	}
+	if c.maxReqPerConn > 0 && c.requests.Add(1)%int64(c.maxReqPerConn) == 0 {
+		req.Close = true
+		c.log(ctx, "info", CategoryConn, i18n.T("client.forcedReconnect", c.maxReqPerConn),
+			map[string]interface{}{"maxRequestsPerConn": c.maxReqPerConn})
+	}
+
+	if c.dumpReqs {
+		c.dumpRequest(ctx, req, botToken)
+	}
+	reqHeaderBytes := requestHeaderBytes(req)
+
+	// В режиме сводки info-записи этапов подавляются, итог пишется одной строкой в конце.
+	// Запрос, уже помеченный тихим (VerboseFirstN), сводку тоже не пишет
+	status := 0
+	if c.traceSummary {
+		if quiet, _ := ctx.Value(quietKey{}).(bool); !quiet {
+			defer func() { c.logTraceSummary(ctx, method, timings, status) }()
+		}
+		ctx = WithQuiet(ctx)
+	}
 
 	// Добавляем трейсинг для детального логирования
 	var (
-		getConnStart              time.Time
+		getConnStart, gotConnAt   time.Time
 		dnsStart, dnsDone         time.Time
 		connectStart, connectDone time.Time
 		tlsStart, tlsDone         time.Time
 		reqStart                  time.Time
 		gotFirstByte              time.Time
 		connReused                bool
+		newConn                   bool
 		remoteAddr                string
 	)
 
-	isProxy := c.proxyURL != ""
+	defer func() {
+		if !dnsDone.IsZero() {
+			timings.DNS = dnsDone.Sub(dnsStart)
+		}
+		if !connectDone.IsZero() {
+			timings.Connect = connectDone.Sub(connectStart)
+		}
+		if !tlsDone.IsZero() {
+			timings.TLS = tlsDone.Sub(tlsStart)
+		}
+		if !gotFirstByte.IsZero() && !reqStart.IsZero() {
+			timings.TTFB = gotFirstByte.Sub(reqStart)
+		}
+		if !reqStart.IsZero() && !gotConnAt.IsZero() {
+			timings.Upload = reqStart.Sub(gotConnAt)
+		}
+		timings.ConnReused = connReused
+		timings.NewConn = newConn
+		if reqStart.IsZero() {
+			timings.BytesSent = 0
+		} else {
+			timings.HeaderBytesSent = reqHeaderBytes
+		}
+	}()
+
+	isProxy := c.proxyURL != "" || c.pool != nil
 
 	trace := &httptrace.ClientTrace{
 		GetConn: func(hostPort string) {
 			getConnStart = time.Now()
+			fields := map[string]interface{}{"hostPort": hostPort, "proxy": isProxy}
 			if isProxy {
-				c.logFunc("info", fmt.Sprintf("📡 GetConn: запрос соединения для %s (через прокси)", hostPort))
+				c.log(ctx, "info", CategoryConn, i18n.T("trace.getConnProxy", hostPort), fields)
 			} else {
-				c.logFunc("info", fmt.Sprintf("📡 GetConn: запрос соединения для %s", hostPort))
+				c.log(ctx, "info", CategoryConn, i18n.T("trace.getConn", hostPort), fields)
 			}
 		},
 		DNSStart: func(info httptrace.DNSStartInfo) {
 			dnsStart = time.Now()
+			fields := map[string]interface{}{"host": info.Host}
 			if isProxy {
-				c.logFunc("info", fmt.Sprintf("🔍 DNS lookup начат для прокси: %s", info.Host))
+				c.log(ctx, "info", CategoryDNS, i18n.T("trace.dnsStartProxy", info.Host), fields)
 			} else {
-				c.logFunc("info", fmt.Sprintf("🔍 DNS lookup начат для: %s", info.Host))
+				c.log(ctx, "info", CategoryDNS, i18n.T("trace.dnsStart", info.Host), fields)
 			}
 		},
 		DNSDone: func(info httptrace.DNSDoneInfo) {
 			dnsDone = time.Now()
+			fields := map[string]interface{}{"durationMs": durationMs(dnsDone.Sub(dnsStart))}
 			if info.Err != nil {
-				c.logFunc("error", fmt.Sprintf("🔍 DNS lookup ошибка: %v (за %v)", info.Err, dnsDone.Sub(dnsStart)))
+				c.log(ctx, "error", CategoryDNS, i18n.T("trace.dnsError", info.Err, dnsDone.Sub(dnsStart)), fields)
 			} else {
 				addrs := make([]string, len(info.Addrs))
 				for i, addr := range info.Addrs {
 					addrs[i] = addr.String()
 				}
-				c.logFunc("info", fmt.Sprintf("🔍 DNS lookup завершён за %v. IP: %v", dnsDone.Sub(dnsStart), addrs))
+				fields["addrs"] = addrs
+				c.log(ctx, "info", CategoryDNS, i18n.T("trace.dnsDone", dnsDone.Sub(dnsStart), addrs), fields)
 			}
 		},
 		ConnectStart: func(network, addr string) {
 			connectStart = time.Now()
+			fields := map[string]interface{}{"network": network, "addr": addr, "proxy": isProxy}
 			if isProxy {
-				c.logFunc("info", fmt.Sprintf("🔗 TCP соединение с ПРОКСИ начато: %s %s", network, addr))
+				c.log(ctx, "info", CategoryTCP, i18n.T("trace.connectStartProxy", network, addr), fields)
 			} else {
-				c.logFunc("info", fmt.Sprintf("🔗 TCP соединение начато: %s %s", network, addr))
+				c.log(ctx, "info", CategoryTCP, i18n.T("trace.connectStart", network, addr), fields)
 			}
 		},
 		ConnectDone: func(network, addr string, err error) {
 			connectDone = time.Now()
+			fields := map[string]interface{}{"addr": addr, "proxy": isProxy, "durationMs": durationMs(connectDone.Sub(connectStart))}
 			if err != nil {
 				if isProxy {
-					c.logFunc("error", fmt.Sprintf("🔗 TCP соединение с ПРОКСИ ошибка: %v (за %v)", err, connectDone.Sub(connectStart)))
+					c.log(ctx, "error", CategoryTCP, i18n.T("trace.connectErrorProxy", err, connectDone.Sub(connectStart)), fields)
 				} else {
-					c.logFunc("error", fmt.Sprintf("🔗 TCP соединение ошибка: %v (за %v)", err, connectDone.Sub(connectStart)))
+					c.log(ctx, "error", CategoryTCP, i18n.T("trace.connectError", err, connectDone.Sub(connectStart)), fields)
 				}
 			} else {
 				if isProxy {
-					c.logFunc("info", fmt.Sprintf("🔗 TCP соединение с ПРОКСИ установлено за %v", connectDone.Sub(connectStart)))
+					c.log(ctx, "info", CategoryTCP, i18n.T("trace.connectDoneProxy", connectDone.Sub(connectStart)), fields)
 				} else {
-					c.logFunc("info", fmt.Sprintf("🔗 TCP соединение установлено за %v", connectDone.Sub(connectStart)))
+					c.log(ctx, "info", CategoryTCP, i18n.T("trace.connectDone", connectDone.Sub(connectStart)), fields)
 				}
 			}
 		},
 		TLSHandshakeStart: func() {
 			tlsStart = time.Now()
 			if isProxy {
-				c.logFunc("info", "🔐 TLS handshake начат (через прокси-туннель к api.telegram.org)")
+				c.log(ctx, "info", CategoryTLS, i18n.T("trace.tlsStartProxy"), nil)
 			} else {
-				c.logFunc("info", "🔐 TLS handshake начат")
+				c.log(ctx, "info", CategoryTLS, i18n.T("trace.tlsStart"), nil)
 			}
 		},
 		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
 			tlsDone = time.Now()
+			fields := map[string]interface{}{"durationMs": durationMs(tlsDone.Sub(tlsStart))}
 			if err != nil {
-				c.logFunc("error", fmt.Sprintf("🔐 TLS handshake ошибка: %v (за %v)", err, tlsDone.Sub(tlsStart)))
+				c.log(ctx, "error", CategoryTLS, i18n.T("trace.tlsError", err, tlsDone.Sub(tlsStart)), fields)
 			} else {
-				c.logFunc("info", fmt.Sprintf("🔐 TLS handshake завершён за %v. Версия: %s, Cipher: %s, ServerName: %s",
+				fields["version"] = tlsVersionString(state.Version)
+				fields["cipher"] = tls.CipherSuiteName(state.CipherSuite)
+				fields["serverName"] = state.ServerName
+				c.log(ctx, "info", CategoryTLS, i18n.T("trace.tlsDone",
 					tlsDone.Sub(tlsStart),
 					tlsVersionString(state.Version),
 					tls.CipherSuiteName(state.CipherSuite),
-					state.ServerName))
+					state.ServerName), fields)
+				if c.tlsDetails {
+					c.logCertificates(ctx, state)
+				}
 			}
 		},
 		GotConn: func(info httptrace.GotConnInfo) {
+			gotConnAt = time.Now()
 			connReused = info.Reused
+			newConn = !info.Reused
 			remoteAddr = info.Conn.RemoteAddr().String()
 			connTime := time.Since(getConnStart)
+			if tc, ok := asTrackedConn(info.Conn); ok {
+				if info.Reused {
+					tc.reused(RequestNumFromContext(ctx), info.IdleTime)
+				} else {
+					tc.used()
+				}
+			}
+			fields := map[string]interface{}{"remoteAddr": remoteAddr, "reused": info.Reused, "durationMs": durationMs(connTime)}
 			if info.Reused {
-				c.logFunc("info", fmt.Sprintf("✅ GotConn: переиспользовано соединение к %s (idle: %v, всего: %v)", remoteAddr, info.IdleTime, connTime))
+				fields["idleMs"] = durationMs(info.IdleTime)
+				c.log(ctx, "info", CategoryConn, i18n.T("trace.gotConnReused", remoteAddr, info.IdleTime, connTime), fields)
 			} else {
-				c.logFunc("info", fmt.Sprintf("✅ GotConn: новое соединение к %s (всего: %v)", remoteAddr, connTime))
+				c.log(ctx, "info", CategoryConn, i18n.T("trace.gotConnNew", remoteAddr, connTime), fields)
 			}
 		},
 		WroteHeaders: func() {
-			c.logFunc("info", "📤 HTTP заголовки отправлены")
+			c.log(ctx, "info", CategoryHTTP, i18n.T("trace.wroteHeaders"), nil)
 		},
 		WroteRequest: func(info httptrace.WroteRequestInfo) {
 			reqStart = time.Now()
 			if info.Err != nil {
-				c.logFunc("error", fmt.Sprintf("📤 Ошибка записи запроса: %v", info.Err))
+				c.log(ctx, "error", CategoryHTTP, i18n.T("trace.wroteRequestError", info.Err), nil)
 			} else {
-				c.logFunc("info", "📤 Запрос полностью отправлен, ожидание ответа...")
+				c.log(ctx, "info", CategoryHTTP, i18n.T("trace.wroteRequest"), nil)
 			}
 		},
 		GotFirstResponseByte: func() {
 			gotFirstByte = time.Now()
-			c.logFunc("info", fmt.Sprintf("📥 Первый байт ответа получен за %v (TTFB)", gotFirstByte.Sub(reqStart)))
+			c.log(ctx, "info", CategoryHTTP, i18n.T("trace.firstByte", gotFirstByte.Sub(reqStart)),
+				map[string]interface{}{"ttfbMs": durationMs(gotFirstByte.Sub(reqStart))})
 		},
 	}
 
 	req = req.WithContext(httptrace.WithClientTrace(ctx, trace))
 
-	c.logFunc("info", "Выполнение HTTP запроса...")
+	c.log(ctx, "info", CategoryHTTP, i18n.T("client.doRequest"), nil)
 	startTime := time.Now()
 
 	resp, err := c.httpClient.Do(req)
 	totalTime := time.Since(startTime)
+	timings.Total = totalTime
 
 	if err != nil {
-		c.logFunc("error", fmt.Sprintf("HTTP запрос ошибка за %v: %v", totalTime, err))
+		c.log(ctx, "error", CategoryHTTP, i18n.T("client.requestError", totalTime, err),
+			map[string]interface{}{"durationMs": durationMs(totalTime)})
 		// Детализируем тип ошибки
 		if ctx.Err() == context.DeadlineExceeded {
-			c.logFunc("error", "Причина: превышен таймаут контекста")
+			c.log(ctx, "error", CategoryHTTP, i18n.T("client.reasonDeadline"), nil)
 		} else if ctx.Err() == context.Canceled {
-			c.logFunc("error", "Причина: контекст отменён")
+			c.log(ctx, "error", CategoryHTTP, i18n.T("client.reasonCanceled"), nil)
 		}
 		if urlErr, ok := err.(*url.Error); ok {
-			c.logFunc("error", fmt.Sprintf("URL Error детали - Op: %s, Timeout: %v", urlErr.Op, urlErr.Timeout()))
+			c.log(ctx, "error", CategoryHTTP, i18n.T("client.urlError", urlErr.Op, urlErr.Timeout()), nil)
 			if urlErr.Unwrap() != nil {
-				c.logFunc("error", fmt.Sprintf("Внутренняя ошибка: %v", urlErr.Unwrap()))
+				c.log(ctx, "error", CategoryHTTP, i18n.T("client.innerError", urlErr.Unwrap()), nil)
 			}
 		}
-		return fmt.Errorf("выполнение запроса: %w", err)
+		return nil, fmt.Errorf("выполнение запроса: %w", err)
 	}
 	defer resp.Body.Close()
+	status = resp.StatusCode
+	timings.HeaderBytesReceived = responseHeaderBytes(resp)
 
-	c.logFunc("info", fmt.Sprintf("📥 Ответ получен. Статус: %d, Время: %v, ConnReused: %v", resp.StatusCode, totalTime, connReused))
+	c.log(ctx, "info", CategoryHTTP, i18n.T("client.responseReceived", resp.StatusCode, totalTime, connReused),
+		map[string]interface{}{"status": resp.StatusCode, "durationMs": durationMs(totalTime), "reused": connReused})
 
 	// Логируем заголовки ответа
-	c.logFunc("info", fmt.Sprintf("📥 Response Headers: Content-Length=%s, Content-Type=%s",
+	c.log(ctx, "info", CategoryHTTP, i18n.T("client.responseHeaders",
 		resp.Header.Get("Content-Length"),
-		resp.Header.Get("Content-Type")))
+		resp.Header.Get("Content-Type")), nil)
 
 	readStart := time.Now()
-	body, err := io.ReadAll(resp.Body)
+	// Без разбора тела ok=false в ответе со статусом 200 не обнаружится; ошибки со статусом читаются как обычно
+	if discard, _ := ctx.Value(discardBodyKey{}).(bool); discard && resp.StatusCode == http.StatusOK {
+		n, err := io.Copy(io.Discard, resp.Body)
+		readTime := time.Since(readStart)
+		timings.BodyRead = readTime
+		timings.BytesReceived = n
+		timings.Total = time.Since(startTime)
+		if err != nil {
+			c.log(ctx, "error", CategoryHTTP, i18n.T("client.readError", readTime, err),
+				map[string]interface{}{"durationMs": durationMs(readTime)})
+			return nil, fmt.Errorf("чтение ответа: %w", err)
+		}
+		c.log(ctx, "info", CategoryHTTP, i18n.T("client.bodyDiscarded", readTime, n),
+			map[string]interface{}{"durationMs": durationMs(readTime), "bytes": n, "discarded": true})
+		return &apiResponse{OK: true}, nil
+	}
+	body, err := readLimited(resp.Body, c.maxBody)
 	readTime := time.Since(readStart)
+	timings.BodyRead = readTime
+	timings.BytesReceived = int64(len(body))
+	timings.Total = time.Since(startTime)
 
 	if err != nil {
-		c.logFunc("error", fmt.Sprintf("Ошибка чтения тела ответа за %v: %v", readTime, err))
-		return fmt.Errorf("чтение ответа: %w", err)
+		c.log(ctx, "error", CategoryHTTP, i18n.T("client.readError", readTime, err),
+			map[string]interface{}{"durationMs": durationMs(readTime)})
+		return nil, fmt.Errorf("чтение ответа: %w", err)
 	}
 
-	c.logFunc("info", fmt.Sprintf("Тело ответа прочитано за %v, размер: %d байт", readTime, len(body)))
+	c.log(ctx, "info", CategoryHTTP, i18n.T("client.bodyRead", readTime, len(body)),
+		map[string]interface{}{"durationMs": durationMs(readTime), "bytes": len(body)})
+
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "" {
+		decoded, ok, err := decodeBody(encoding, body, c.maxBody)
+		switch {
+		case err != nil:
+			// Нераспаковываемое тело разбирается как есть: для диагностики это лучше, чем потерять ответ
+			c.log(ctx, "warn", CategoryHTTP, i18n.T("client.decodeError", encoding, err),
+				map[string]interface{}{"encoding": encoding})
+		case ok:
+			c.log(ctx, "info", CategoryHTTP, i18n.T("client.bodyDecoded", encoding, len(body), len(decoded)),
+				map[string]interface{}{"encoding": encoding, "bytes": len(decoded)})
+			body = decoded
+		}
+	}
 
 	if resp.StatusCode != http.StatusOK {
-		c.logFunc("error", fmt.Sprintf("Telegram API ошибка: status=%d, body=%s", resp.StatusCode, string(body)))
-		return errors.New(fmt.Sprintf("status is not ok: %d, body: %s", resp.StatusCode, string(body)))
+		c.log(ctx, "error", CategoryHTTP, i18n.T("client.apiError", resp.StatusCode, string(body)),
+			map[string]interface{}{"status": resp.StatusCode})
+		// Ошибки Bot API приходят JSON-объектом с ok=false; остальное (например, страница прокси) — как есть
+		var errResp apiResponse
+		if err := json.Unmarshal(body, &errResp); err == nil && !errResp.OK && errResp.ErrorCode != 0 {
+			return nil, newAPIError(resp.StatusCode, &errResp)
+		}
+		return nil, errors.New(fmt.Sprintf("status is not ok: %d, body: %s", resp.StatusCode, string(body)))
 	}
 
-	c.logFunc("info", fmt.Sprintf("Запрос успешен. Общее время: %v", totalTime))
-	return nil
+	var apiResp apiResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		// Статус 200 считаем успехом, даже если тело не удалось разобрать
+		c.log(ctx, "warn", CategoryHTTP, i18n.T("client.jsonParseError", err), nil)
+		return &apiResponse{OK: true}, nil
+	}
+	if !apiResp.OK {
+		c.log(ctx, "error", CategoryHTTP, i18n.T("client.okFalse", apiResp.ErrorCode, apiResp.Description),
+			map[string]interface{}{"errorCode": apiResp.ErrorCode})
+		return nil, newAPIError(resp.StatusCode, &apiResp)
+	}
+
+	c.log(ctx, "info", CategoryHTTP, i18n.T("client.requestSuccess", totalTime),
+		map[string]interface{}{"durationMs": durationMs(totalTime)})
+	return &apiResp, nil
+}
+
+// encodeParams кодирует параметры запроса в выбранную кодировку
+func encodeParams(encoding string, data url.Values) ([]byte, string, error) {
+	switch encoding {
+	case EncodingJSON:
+		obj := make(map[string]interface{}, len(data))
+		for key := range data {
+			value := data.Get(key)
+			// Булевы флаги передаём как JSON bool, JSON-объекты и массивы — как есть, остальное — строками
+			switch {
+			case value == "True" || value == "true":
+				obj[key] = true
+			case value == "False" || value == "false":
+				obj[key] = false
+			case strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{"):
+				if json.Valid([]byte(value)) {
+					obj[key] = json.RawMessage(value)
+				} else {
+					obj[key] = value
+				}
+			default:
+				obj[key] = value
+			}
+		}
+		body, err := json.Marshal(obj)
+		return body, "application/json", err
+	case EncodingMultipart:
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for key := range data {
+			if err := w.WriteField(key, data.Get(key)); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), w.FormDataContentType(), nil
+	default:
+		return []byte(data.Encode()), "application/x-www-form-urlencoded", nil
+	}
+}
+
+// log пишет запись лога клиента с номером запроса из контекста
+func (c *Client) log(ctx context.Context, level, category, message string, fields map[string]interface{}) {
+	logCtx(c.logFunc, ctx, level, category, message, fields)
+}
+
+// logTraceSummary пишет одной строкой итоги этапов HTTP-запроса (WithTraceSummary).
+// Невыполненные этапы (например, DNS при переиспользованном соединении) отмечаются «-»
+func (c *Client) logTraceSummary(ctx context.Context, method string, t *Timings, status int) {
+	statusText := "-"
+	if status != 0 {
+		statusText = strconv.Itoa(status)
+	}
+	c.logFunc("info", i18n.T("trace.summary", RequestNumFromContext(ctx), method, t.ConnReused,
+		summaryMs(t.DNS), summaryMs(t.Connect), summaryMs(t.TLS), summaryMs(t.TTFB), summaryMs(t.Total), statusText),
+		LogMeta{
+			RequestNum: RequestNumFromContext(ctx),
+			Category:   CategoryHTTP,
+			Fields: map[string]interface{}{
+				"method":    method,
+				"reused":    t.ConnReused,
+				"dnsMs":     durationMs(t.DNS),
+				"connectMs": durationMs(t.Connect),
+				"tlsMs":     durationMs(t.TLS),
+				"ttfbMs":    durationMs(t.TTFB),
+				"totalMs":   durationMs(t.Total),
+				"status":    status,
+			},
+		})
+}
+
+// summaryMs форматирует длительность этапа для строки сводки
+func summaryMs(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(durationMs(d), 'f', 1, 64) + "ms"
+}
+
+// requestHeaderBytes оценивает размер стартовой строки и заголовков запроса в HTTP/1.1.
+// Заголовки, которые транспорт добавляет сам (Host, Content-Length, User-Agent), учитываются отдельно
+func requestHeaderBytes(req *http.Request) int64 {
+	n := len(req.Method) + len(req.URL.RequestURI()) + len(" HTTP/1.1\r\n") + 1
+	n += len("Host: \r\n") + len(req.URL.Host)
+	n += len("Content-Length: \r\n") + len(strconv.FormatInt(req.ContentLength, 10))
+	if req.Header.Get("User-Agent") == "" {
+		n += len("User-Agent: Go-http-client/1.1\r\n")
+	}
+	return int64(n) + headerBytes(req.Header)
+}
+
+// responseHeaderBytes оценивает размер стартовой строки и заголовков ответа
+func responseHeaderBytes(resp *http.Response) int64 {
+	n := len(resp.Proto) + len(" ") + len(resp.Status) + len("\r\n")
+	return int64(n) + headerBytes(resp.Header)
+}
+
+// headerBytes размер заголовков в виде "Key: value\r\n" плюс пустая строка в конце
+func headerBytes(h http.Header) int64 {
+	n := len("\r\n")
+	for key, values := range h {
+		for _, v := range values {
+			n += len(key) + len(": ") + len(v) + len("\r\n")
+		}
+	}
+	return int64(n)
 }
 
 // tlsVersionString возвращает строковое представление версии TLS
@@ -310,4 +1289,3 @@ func tlsVersionString(version uint16) string {
 		return fmt.Sprintf("Unknown (0x%04x)", version)
 	}
 }
-
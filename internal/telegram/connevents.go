@@ -0,0 +1,100 @@
+package telegram
+
+import (
+	"crypto/tls"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// CategoryConnEvent категория событий жизни соединения (поле connEvent); их отдаёт
+// отдельный SSE-поток /api/conns, и они не подавляются тихим режимом запроса
+const CategoryConnEvent = "connEvent"
+
+// События жизни соединения
+const (
+	ConnEventOpen  = "conn_open"
+	ConnEventReuse = "conn_reuse"
+	ConnEventClose = "conn_close"
+)
+
+// connSeq нумерует соединения, чтобы события одного соединения можно было связать
+var connSeq atomic.Int64
+
+// trackedConn соединение дайлера клиента (с прокси — соединение с прокси, внутри которого
+// туннель): помнит, когда открыто и когда использовалось, и сообщает о закрытии
+type trackedConn struct {
+	net.Conn
+	id       int64
+	logFunc  LogFunc
+	opened   time.Time
+	mu       sync.Mutex
+	lastUsed time.Time
+	requests int
+	closed   sync.Once
+}
+
+// newTrackedConn оборачивает соединение и пишет событие conn_open
+func newTrackedConn(conn net.Conn, logFunc LogFunc, requestNum int) *trackedConn {
+	now := time.Now()
+	tc := &trackedConn{Conn: conn, id: connSeq.Add(1), logFunc: logFunc, opened: now, lastUsed: now}
+	tc.event(requestNum, ConnEventOpen, i18n.T("conn.open", tc.id, conn.RemoteAddr(), conn.LocalAddr()), map[string]interface{}{
+		"localAddr": conn.LocalAddr().String(),
+	})
+	return tc
+}
+
+// reused отмечает выдачу соединения запросу через keep-alive и пишет событие conn_reuse
+func (tc *trackedConn) reused(requestNum int, idle time.Duration) {
+	tc.mu.Lock()
+	tc.lastUsed = time.Now()
+	tc.requests++
+	requests := tc.requests
+	tc.mu.Unlock()
+	tc.event(requestNum, ConnEventReuse, i18n.T("conn.reuse", tc.id, tc.RemoteAddr(), time.Since(tc.opened).Round(time.Millisecond), idle.Round(time.Millisecond), requests),
+		map[string]interface{}{"idleMs": durationMs(idle), "requests": requests})
+}
+
+// used отмечает первый запрос по новому соединению
+func (tc *trackedConn) used() {
+	tc.mu.Lock()
+	tc.lastUsed = time.Now()
+	tc.requests++
+	tc.mu.Unlock()
+}
+
+// Close закрывает соединение и один раз пишет событие conn_close с возрастом соединения и
+// временем с последнего использования: по нему видно, через сколько простоя прокси рвёт соединения
+func (tc *trackedConn) Close() error {
+	err := tc.Conn.Close()
+	tc.closed.Do(func() {
+		tc.mu.Lock()
+		sinceUsed, requests := time.Since(tc.lastUsed), tc.requests
+		tc.mu.Unlock()
+		tc.event(0, ConnEventClose, i18n.T("conn.close", tc.id, tc.RemoteAddr(), time.Since(tc.opened).Round(time.Millisecond),
+			sinceUsed.Round(time.Millisecond), requests), map[string]interface{}{"sinceUsedMs": durationMs(sinceUsed), "requests": requests})
+	})
+	return err
+}
+
+// event пишет событие жизни соединения с общими полями
+func (tc *trackedConn) event(requestNum int, event, message string, fields map[string]interface{}) {
+	fields["connEvent"] = event
+	fields["connID"] = tc.id
+	fields["remoteAddr"] = tc.RemoteAddr().String()
+	fields["ageMs"] = durationMs(time.Since(tc.opened))
+	tc.logFunc("info", message, LogMeta{RequestNum: requestNum, Category: CategoryConnEvent, Fields: fields})
+}
+
+// asTrackedConn находит trackedConn под соединением из GotConnInfo: для HTTPS транспорт
+// выдаёт tls.Conn поверх соединения дайлера
+func asTrackedConn(conn net.Conn) (*trackedConn, bool) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	tc, ok := conn.(*trackedConn)
+	return tc, ok
+}
@@ -0,0 +1,44 @@
+package telegram
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// decodeBody распаковывает тело ответа по заголовку Content-Encoding. Accept-Encoding
+// выставляется вручную, поэтому транспорт сам ответы не распаковывает — ни от Bot API,
+// ни собственные страницы прокси (например, 407). Предел limit относится к распакованному
+// телу, чтобы сжатый ответ не обошёл MaxResponseBody. Неизвестная кодировка — тело как есть
+func decodeBody(encoding string, body []byte, limit int64) ([]byte, bool, error) {
+	var r io.Reader
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, false, fmt.Errorf("распаковка gzip: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	case "deflate":
+		// По RFC 9110 deflate — это zlib, но часть серверов шлёт «сырой» deflate без заголовка
+		zr, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			r = flate.NewReader(bytes.NewReader(body))
+		} else {
+			defer zr.Close()
+			r = zr
+		}
+	default:
+		return body, false, nil
+	}
+	decoded, err := readLimited(r, limit)
+	if err != nil {
+		return nil, false, fmt.Errorf("распаковка %s: %w", encoding, err)
+	}
+	return decoded, true, nil
+}
@@ -0,0 +1,97 @@
+package telegram
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// proxyPool выбирает прокси для каждого HTTP-запроса из пула с закреплением сессии: один прокси
+// обслуживает stickiness запросов подряд, затем выбирается случайный другой. Повтор того же
+// запроса (тот же номер в контексте) остаётся на текущем прокси и не расходует закрепление
+type proxyPool struct {
+	// proxies адреса пула; nil — прямое подключение
+	proxies    []*url.URL
+	stickiness int
+	logFunc    LogFunc
+
+	mu      sync.Mutex
+	current int
+	used    int
+	lastReq int
+}
+
+// newProxyPool разбирает адреса пула; пустая строка — прямое подключение
+func newProxyPool(proxies []string, stickiness int, logFunc LogFunc) (*proxyPool, error) {
+	p := &proxyPool{stickiness: stickiness, logFunc: logFunc, current: rand.Intn(len(proxies))}
+	for _, proxy := range proxies {
+		if proxy == "" {
+			p.proxies = append(p.proxies, nil)
+			continue
+		}
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка парсинга прокси URL: %w", err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("прокси URL %q должен содержать схему и хост", u.Redacted())
+		}
+		p.proxies = append(p.proxies, u)
+	}
+	return p, nil
+}
+
+// proxy функция выбора прокси для http.Transport. Транспорт держит соединения отдельно для
+// каждого прокси, поэтому после смены прокси keep-alive начинается заново
+func (p *proxyPool) proxy(req *http.Request) (*url.URL, error) {
+	requestNum := RequestNumFromContext(req.Context())
+	p.mu.Lock()
+	switched, from, used := false, p.current, p.used
+	if requestNum == 0 || requestNum != p.lastReq {
+		if p.used >= p.stickiness && len(p.proxies) > 1 {
+			next := rand.Intn(len(p.proxies) - 1)
+			if next >= p.current {
+				next++
+			}
+			p.current, p.used, switched = next, 0, true
+		}
+		p.used++
+		p.lastReq = requestNum
+	}
+	current := p.current
+	p.mu.Unlock()
+
+	if switched {
+		p.logFunc("info", i18n.T("client.proxySwitch", proxyName(p.proxies[from]), proxyName(p.proxies[current]), used), LogMeta{
+			RequestNum: requestNum,
+			Category:   CategoryProxy,
+			Fields: map[string]interface{}{
+				"proxyURL":      proxyLabel(p.proxies[current]),
+				"proxyIndex":    current,
+				"previousProxy": proxyLabel(p.proxies[from]),
+				"stickyUsed":    used,
+			},
+		})
+	}
+	return p.proxies[current], nil
+}
+
+// proxyLabel адрес прокси для лога без пароля; пустой — прямое подключение
+func proxyLabel(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.Redacted()
+}
+
+// proxyName подпись прокси в тексте лога: адрес без пароля или «прямое подключение»
+func proxyName(u *url.URL) string {
+	if u == nil {
+		return i18n.T("client.proxyDirect")
+	}
+	return u.Redacted()
+}
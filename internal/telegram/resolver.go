@@ -0,0 +1,122 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// dnsTimeout ограничивает один DNS-запрос к заданному серверу или DoH
+const dnsTimeout = 10 * time.Second
+
+// WithDNSServer направляет DNS-запросы дайлера на сервер addr (host:port) вместо системного резолвера
+func WithDNSServer(addr string) Option {
+	return func(c *Client) {
+		c.dnsServer = addr
+	}
+}
+
+// WithDoH выполняет DNS-запросы через DNS-over-HTTPS (RFC 8484) по адресу endpoint,
+// например https://1.1.1.1/dns-query. Имеет приоритет над WithDNSServer
+func WithDoH(endpoint string) Option {
+	return func(c *Client) {
+		c.dohEndpoint = endpoint
+	}
+}
+
+// newResolver создаёт резолвер по настройкам клиента; nil — системный резолвер
+func (c *Client) newResolver() *net.Resolver {
+	switch {
+	case c.dohEndpoint != "":
+		httpClient := &http.Client{Timeout: dnsTimeout}
+		return &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return &dohConn{ctx: ctx, endpoint: c.dohEndpoint, client: httpClient}, nil
+			},
+		}
+	case c.dnsServer != "":
+		dialer := &net.Dialer{Timeout: dnsTimeout}
+		return &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, c.dnsServer)
+			},
+		}
+	default:
+		return nil
+	}
+}
+
+// dohConn отдаёт резолверу Go ответы DoH под видом потокового DNS-соединения:
+// каждое сообщение предваряется двухбайтовой длиной, как в DNS over TCP
+type dohConn struct {
+	ctx      context.Context
+	endpoint string
+	client   *http.Client
+	resp     bytes.Buffer
+	deadline time.Time
+}
+
+// Write принимает DNS-запрос с префиксом длины и сразу выполняет его через DoH
+func (d *dohConn) Write(b []byte) (int, error) {
+	if len(b) < 2 || int(binary.BigEndian.Uint16(b)) != len(b)-2 {
+		return 0, errors.New("DoH: неполное DNS-сообщение")
+	}
+
+	ctx := d.ctx
+	if !d.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, d.deadline)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.endpoint, bytes.NewReader(b[2:]))
+	if err != nil {
+		return 0, fmt.Errorf("DoH: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("DoH: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("DoH: статус %d", resp.StatusCode)
+	}
+	// DNS-сообщение с 2-байтовой длиной не бывает больше 65535 байт
+	msg, err := readLimited(resp.Body, 65535)
+	if err != nil {
+		return 0, fmt.Errorf("DoH: %w", err)
+	}
+
+	var length [2]byte
+	binary.BigEndian.PutUint16(length[:], uint16(len(msg)))
+	d.resp.Write(length[:])
+	d.resp.Write(msg)
+	return len(b), nil
+}
+
+// Read возвращает ответ последнего запроса
+func (d *dohConn) Read(b []byte) (int, error) {
+	return d.resp.Read(b)
+}
+
+func (d *dohConn) Close() error                       { return nil }
+func (d *dohConn) LocalAddr() net.Addr                { return dohAddr{} }
+func (d *dohConn) RemoteAddr() net.Addr               { return dohAddr{} }
+func (d *dohConn) SetDeadline(t time.Time) error      { d.deadline = t; return nil }
+func (d *dohConn) SetReadDeadline(t time.Time) error  { return nil }
+func (d *dohConn) SetWriteDeadline(t time.Time) error { d.deadline = t; return nil }
+
+// dohAddr адрес-заглушка для dohConn
+type dohAddr struct{}
+
+func (dohAddr) Network() string { return "doh" }
+func (dohAddr) String() string  { return "doh" }
@@ -0,0 +1,59 @@
+package telegram
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// DefaultTLSExpiryWarning за сколько до истечения сертификата предупреждать, если окно не задано
+const DefaultTLSExpiryWarning = 30 * 24 * time.Hour
+
+// tlsTimeLayout формат дат сертификата в логе
+const tlsTimeLayout = "2006-01-02 15:04 MST"
+
+// WithTLSDetails включает логирование цепочки сертификатов после каждого TLS handshake и
+// предупреждение, если сертификат истекает раньше чем через expiryWarning (0 — DefaultTLSExpiryWarning)
+func WithTLSDetails(enabled bool, expiryWarning time.Duration) Option {
+	return func(c *Client) {
+		c.tlsDetails = enabled
+		c.tlsExpiryWarning = expiryWarning
+		if expiryWarning <= 0 {
+			c.tlsExpiryWarning = DefaultTLSExpiryWarning
+		}
+	}
+}
+
+// logCertificates логирует субъект, издателя, срок действия и SAN сертификатов цепочки
+func (c *Client) logCertificates(ctx context.Context, state tls.ConnectionState) {
+	now := time.Now()
+	for i, cert := range state.PeerCertificates {
+		sans := append([]string{}, cert.DNSNames...)
+		for _, ip := range cert.IPAddresses {
+			sans = append(sans, ip.String())
+		}
+		fields := map[string]interface{}{
+			"certIndex": i,
+			"subject":   cert.Subject.String(),
+			"issuer":    cert.Issuer.String(),
+			"notBefore": cert.NotBefore,
+			"notAfter":  cert.NotAfter,
+		}
+		if i == 0 {
+			fields["sans"] = sans
+			c.log(ctx, "info", CategoryTLS, i18n.T("trace.tlsLeaf", cert.Subject.String(), cert.Issuer.String(),
+				cert.NotBefore.Format(tlsTimeLayout), cert.NotAfter.Format(tlsTimeLayout), strings.Join(sans, ", ")), fields)
+		} else {
+			c.log(ctx, "info", CategoryTLS, i18n.T("trace.tlsChainCert", i, cert.Subject.String(), cert.Issuer.String(),
+				cert.NotBefore.Format(tlsTimeLayout), cert.NotAfter.Format(tlsTimeLayout)), fields)
+		}
+		if left := cert.NotAfter.Sub(now); left < c.tlsExpiryWarning {
+			fields["expiresInHours"] = int(left.Hours())
+			c.log(ctx, "warn", CategoryTLS, i18n.T("trace.tlsCertExpiring", cert.Subject.String(),
+				cert.NotAfter.Format(tlsTimeLayout), left.Round(time.Hour)), fields)
+		}
+	}
+}
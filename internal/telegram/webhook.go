@@ -0,0 +1,57 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"SendMsgTestForTG/internal/i18n"
+)
+
+// WebhookInfo результат getWebhookInfo. Пустой URL — вебхук не установлен
+type WebhookInfo struct {
+	URL                  string   `json:"url"`
+	HasCustomCertificate bool     `json:"has_custom_certificate"`
+	PendingUpdateCount   int      `json:"pending_update_count"`
+	IPAddress            string   `json:"ip_address,omitempty"`
+	LastErrorDate        int64    `json:"last_error_date,omitempty"`
+	LastErrorMessage     string   `json:"last_error_message,omitempty"`
+	MaxConnections       int      `json:"max_connections,omitempty"`
+	AllowedUpdates       []string `json:"allowed_updates,omitempty"`
+}
+
+// GetWebhookInfo вызывает getWebhookInfo: с установленным вебхуком getUpdates не работает,
+// и поведение бота при проверке отправки может удивить
+func (c *Client) GetWebhookInfo(ctx context.Context, botToken string) (*WebhookInfo, error) {
+	apiResp, err := c.call(ctx, botToken, "getWebhookInfo", url.Values{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var info WebhookInfo
+	if err := json.Unmarshal(apiResp.Result, &info); err != nil {
+		return nil, fmt.Errorf("разбор getWebhookInfo: %w", err)
+	}
+	if info.URL == "" {
+		c.log(ctx, "info", CategoryHTTP, i18n.T("client.webhookNone", info.PendingUpdateCount),
+			map[string]interface{}{"pendingUpdateCount": info.PendingUpdateCount})
+	} else {
+		c.log(ctx, "warn", CategoryHTTP, i18n.T("client.webhookSet", info.URL, info.PendingUpdateCount),
+			map[string]interface{}{"webhookURL": info.URL, "pendingUpdateCount": info.PendingUpdateCount})
+	}
+	return &info, nil
+}
+
+// DeleteWebhook снимает вебхук методом deleteWebhook; dropPending удаляет и накопленные обновления
+func (c *Client) DeleteWebhook(ctx context.Context, botToken string, dropPending bool) error {
+	data := url.Values{}
+	if dropPending {
+		data.Add("drop_pending_updates", "True")
+	}
+	if _, err := c.call(ctx, botToken, "deleteWebhook", data, nil); err != nil {
+		return err
+	}
+	c.log(ctx, "info", CategoryHTTP, i18n.T("client.webhookDeleted", dropPending),
+		map[string]interface{}{"dropPendingUpdates": dropPending})
+	return nil
+}